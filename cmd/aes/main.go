@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
 
 	"github.com/mas9612/cryptostudy/pkg/aes"
 	"github.com/mas9612/cryptostudy/pkg/util"
@@ -13,10 +16,15 @@ import (
 func main() {
 	fs := flag.NewFlagSet("AES", flag.ExitOnError)
 	key := fs.String("K", "", "Encrypt key (hexadecimal notation)")
-	mode := fs.String("mode", "", "Cipher mode. Valid mode is one of [ECB, CBC, CFB, OFB, CTR, CBC_CTS]")
+	mode := fs.String("mode", "", "Cipher mode. Valid mode is one of [ECB, CBC, CFB, CFB8, CFB1, OFB, CTR, CBC_CTS, GCM, CCM, XTS]. ECB, CBC, CFB, CFB8, CFB1, OFB and CTR process stdin chunk by chunk; the others read whole stdin into memory")
 	iv := fs.String("iv", "", "IV")
-	round := fs.Int("r", -1, "Print round N result")
+	sector := fs.Uint64("sector", 0, "Sector number used as tweak in XTS mode")
+	paddingName := fs.String("padding", "PKCS7", "Padding used in ECB and CBC mode. Valid padding is one of [PKCS7, ANSIX923, ISO7816-4, ISO10126, ZERO, NONE]")
+	trace := fs.String("trace", "", "Trace every step of rounds to stderr. Valid format is one of [text, json]")
+	round := fs.String("r", "", "Trace only round N (number or all). Text format is used if -trace is not specified")
 	decrypt := fs.Bool("d", false, "Decrypt (Default Encrypt)")
+	invertSchedule := fs.Bool("invert-schedule", false, "Recover the cipher key from the round key given by -K and print it")
+	keyRound := fs.Int("key-round", 0, "Round of the round key used with -invert-schedule. -K must hold Nk words starting at the round key of round N")
 	help := fs.Bool("help", false, "Print help and exit")
 
 	err := fs.Parse(os.Args[1:])
@@ -33,17 +41,32 @@ func main() {
 	if *key == "" {
 		fmt.Println("Missing -K")
 		os.Exit(1)
+	} else if *mode == "XTS" {
+		if len(*key) != 64 && len(*key) != 128 {
+			fmt.Println("Key must be one of 32, 64 bytes length in XTS mode: ", len(*key)/2)
+			os.Exit(1)
+		}
 	} else if len(*key) != 32 && len(*key) != 48 && len(*key) != 64 {
 		fmt.Println("Key must be one of 16, 24, 32 bytes length: ", len(*key)/2)
 		os.Exit(1)
 	}
 
+	if *invertSchedule {
+		cipherKey, err := aes.InvertKeySchedule(util.HexStringToBytes(*key), aes.BlockSize128**keyRound)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("%x\n", cipherKey)
+		os.Exit(0)
+	}
+
 	if *mode == "" {
 		fmt.Println("Missing -mode")
 		os.Exit(1)
 	}
 	switch *mode {
-	case "CBC", "CBC_CTS", "CFB", "OFB", "CTR":
+	case "CBC", "CBC_CTS", "CFB", "CFB8", "CFB1", "OFB", "CTR":
 		if *iv == "" {
 			fmt.Println("Missing -iv")
 			os.Exit(1)
@@ -51,7 +74,12 @@ func main() {
 			fmt.Println("IV must be 16 bytes length")
 			os.Exit(1)
 		}
-	case "ECB":
+	case "GCM", "CCM":
+		if *iv == "" {
+			fmt.Println("Missing -iv")
+			os.Exit(1)
+		}
+	case "ECB", "XTS":
 	default:
 		fmt.Println("Invalid mode")
 		os.Exit(1)
@@ -67,25 +95,172 @@ func main() {
 		cipherMode = aes.ModeCBCCTS
 	case "CFB":
 		cipherMode = aes.ModeCFB
+	case "CFB8":
+		cipherMode = aes.ModeCFB8
+	case "CFB1":
+		cipherMode = aes.ModeCFB1
 	case "OFB":
 		cipherMode = aes.ModeOFB
 	case "CTR":
 		cipherMode = aes.ModeCTR
+	case "GCM":
+		cipherMode = aes.ModeGCM
+	case "CCM":
+		cipherMode = aes.ModeCCM
+	case "XTS":
+		cipherMode = aes.ModeXTS
 	}
 
-	aes.PrintNRound = *round
+	var padding aes.Padding
+	switch *paddingName {
+	case "PKCS7":
+		padding = aes.PKCS7Padding{}
+	case "ANSIX923":
+		padding = aes.ANSIX923Padding{}
+	case "ISO7816-4":
+		padding = aes.ISO78164Padding{}
+	case "ISO10126":
+		padding = aes.ISO10126Padding{}
+	case "ZERO":
+		padding = aes.ZeroPadding{}
+	case "NONE":
+		padding = aes.NoPadding{}
+	default:
+		fmt.Println("Invalid padding")
+		os.Exit(1)
+	}
 
-	bytes, err := ioutil.ReadAll(os.Stdin)
+	var tracer aes.Tracer
+	var traceOutput traceWriter
+	if *trace != "" || *round != "" {
+		tracer, traceOutput, err = newTracer(*trace, *round)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if cipherMode == aes.ModeXTS {
+		err = xts(util.HexStringToBytes(*key), aes.SectorTweak(*sector), tracer, *decrypt)
+	} else {
+		err = run(util.HexStringToBytes(*key), cipherMode, util.HexStringToBytes(*iv), padding, tracer, *decrypt)
+	}
+	if err == nil && traceOutput != nil {
+		err = traceOutput.Err()
+	}
 	if err != nil {
-		fmt.Println("Failed to read from stdin")
+		fmt.Println(err)
 		os.Exit(1)
 	}
+}
+
+// traceWriter is the tracer which writes trace events and reports the write error
+type traceWriter interface {
+	aes.Tracer
+	Err() error
+}
+
+// newTracer returns tracer of given format which traces given round, and the underlying tracer which writes events
+func newTracer(format, round string) (aes.Tracer, traceWriter, error) {
+	var w traceWriter
+	switch format {
+	case "", "text":
+		w = aes.NewTextTracer(os.Stderr)
+	case "json":
+		w = aes.NewJSONTracer(os.Stderr)
+	default:
+		return nil, nil, errors.New("invalid trace format")
+	}
+
+	if round == "" || round == "all" {
+		return w, w, nil
+	}
+	n, err := strconv.Atoi(round)
+	if err != nil || n < 0 {
+		return nil, nil, errors.New("invalid round")
+	}
+	return aes.NewRoundFilter(w, n), w, nil
+}
+
+// run encrypts or decrypts stdin with Block traced by given tracer
+func run(key []byte, mode int, iv []byte, padding aes.Padding, tracer aes.Tracer, decrypt bool) error {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	b.SetTracer(tracer)
+
+	switch mode {
+	case aes.ModeECB, aes.ModeCBC, aes.ModeCFB, aes.ModeCFB8, aes.ModeCFB1, aes.ModeOFB, aes.ModeCTR:
+		// process stdin chunk by chunk so that memory use doesn't depend on input size
+		return stream(b, mode, iv, padding, decrypt)
+	}
+	return block(b, mode, iv, padding, decrypt)
+}
+
+// stream encrypts or decrypts stdin with streaming API and writes the result to stdout
+func stream(b *aes.Block, mode int, iv []byte, padding aes.Padding, decrypt bool) error {
+	if decrypt {
+		r, err := aes.NewDecryptReaderWithBlock(os.Stdin, b, mode, iv, padding)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(os.Stdout, r)
+		return err
+	}
+
+	w, err := aes.NewEncryptWriterWithBlock(os.Stdout, b, mode, iv, padding)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, os.Stdin); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// block reads whole stdin, encrypts or decrypts it and writes the result to stdout
+func block(b *aes.Block, mode int, iv []byte, padding aes.Padding, decrypt bool) error {
+	bytes, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return errors.New("failed to read from stdin")
+	}
 
 	var result []byte
-	if !*decrypt {
-		result = aes.Cipher(bytes, util.HexStringToBytes(*key), cipherMode, util.HexStringToBytes(*iv))
+	if !decrypt {
+		result, err = aes.CipherWithBlock(b, bytes, mode, iv, padding)
 	} else {
-		result = aes.InvCipher(bytes, util.HexStringToBytes(*key), cipherMode, util.HexStringToBytes(*iv))
+		result, err = aes.InvCipherWithBlock(b, bytes, mode, iv, padding)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(result))
+	return nil
+}
+
+// xts reads whole stdin, encrypts or decrypts it with XTS mode and writes the result to stdout
+func xts(key, tweak []byte, tracer aes.Tracer, decrypt bool) error {
+	x, err := aes.NewXTS(key)
+	if err != nil {
+		return err
+	}
+	x.SetTracer(tracer)
+
+	bytes, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return errors.New("failed to read from stdin")
+	}
+
+	var result []byte
+	if !decrypt {
+		result, err = x.Encrypt(bytes, tweak)
+	} else {
+		result, err = x.Decrypt(bytes, tweak)
+	}
+	if err != nil {
+		return err
 	}
 	fmt.Print(string(result))
+	return nil
 }
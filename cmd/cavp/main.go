@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+	"github.com/mas9612/cryptostudy/pkg/cavp"
+)
+
+func main() {
+	implName := flag.String("impl", "reference", "Implementation of AES. Valid implementation is one of [reference, ttable, bitsliced]")
+	skipMCT := flag.Bool("skip-mct", false, "Skip Monte Carlo tests")
+	reseed := flag.Bool("reseed", false, "Run each outer iteration of Monte Carlo tests from the inputs in the file to locate the failing one (diagnostic)")
+	verbose := flag.Bool("v", false, "Print every failed vector")
+	help := flag.Bool("help", false, "Print help and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] <.rsp file or directory>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *help {
+		flag.Usage()
+		os.Exit(0)
+	}
+	if flag.NArg() == 0 {
+		fmt.Println("Missing .rsp file or directory")
+		os.Exit(1)
+	}
+
+	impl, err := implementation(*implName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	files, err := responseFiles(flag.Args())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	passed, failed := 0, 0
+	for _, path := range files {
+		f, err := cavp.ParseFile(path)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if f.Test == cavp.TestMCT && *skipMCT {
+			fmt.Printf("SKIP %s\n", f.Name)
+			continue
+		}
+
+		run := cavp.Run
+		if *reseed {
+			run = cavp.RunReseeded
+		}
+		result, err := run(f, impl)
+		if err != nil {
+			fmt.Printf("ERROR %s: %v\n", f.Name, err)
+			failed++
+			continue
+		}
+		status := "PASS"
+		if result.OK() {
+			passed++
+		} else {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Printf("%s %s (%d/%d)\n", status, result.Name, result.Passed, result.Passed+result.Failed)
+		if *verbose {
+			for _, failure := range result.Failures {
+				direction := "ENCRYPT"
+				if failure.Decrypt {
+					direction = "DECRYPT"
+				}
+				fmt.Printf("    [%s] COUNT = %d: %s = %x, expected %x\n", direction, failure.Count, failure.Field, failure.Output, failure.Expected)
+			}
+		}
+	}
+
+	fmt.Printf("%d/%d files passed\n", passed, passed+failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// implementation returns the implementation of given name
+func implementation(name string) (aes.Implementation, error) {
+	switch name {
+	case "reference":
+		return aes.ImplementationReference, nil
+	case "ttable":
+		return aes.ImplementationTTable, nil
+	case "bitsliced":
+		return aes.ImplementationBitsliced, nil
+	}
+	return 0, errors.New("invalid implementation")
+}
+
+// responseFiles returns given files and .rsp files in given directories
+func responseFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.rsp"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/mas9612/cryptostudy/pkg/paddingoracle"
+	"github.com/mas9612/cryptostudy/pkg/util"
+)
+
+func main() {
+	serve := flag.String("serve", "", "Serve the padding oracle with random key at given address (e.g. localhost:8080)")
+	message := flag.String("m", "Padding oracle attack recovers this message without the key", "Message encrypted by the oracle in demo and -serve")
+	oracleURL := flag.String("url", "", "URL of the oracle server. The local oracle is attacked if not specified")
+	iv := flag.String("iv", "", "IV of the cipher text to decrypt with -url (hexadecimal notation)")
+	cipherText := flag.String("c", "", "Cipher text to decrypt with -url (hexadecimal notation)")
+	forge := flag.String("forge", "", "Forge the encryption of given plain text instead of decryption")
+	help := flag.Bool("help", false, "Print help and exit")
+	flag.Parse()
+	if *help {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	var err error
+	switch {
+	case *serve != "":
+		err = serveOracle(*serve, []byte(*message))
+	case *oracleURL != "":
+		if *forge == "" && (*iv == "" || *cipherText == "") {
+			fmt.Println("Missing -iv or -c")
+			os.Exit(1)
+		}
+		err = attack(paddingoracle.NewHTTPOracle(*oracleURL, nil), util.HexStringToBytes(*iv), util.HexStringToBytes(*cipherText), *forge)
+	default:
+		err = demo([]byte(*message), *forge)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// newOracle returns the local oracle with random key
+func newOracle() (*paddingoracle.LocalOracle, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return paddingoracle.NewLocalOracle(key)
+}
+
+// serveOracle prints the encrypted message and serves the local oracle over HTTP
+func serveOracle(addr string, message []byte) error {
+	oracle, err := newOracle()
+	if err != nil {
+		return err
+	}
+	iv, cipherText, err := oracle.Encrypt(message)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("IV:          %x\n", iv)
+	fmt.Printf("Cipher text: %x\n", cipherText)
+	fmt.Printf("Serving padding oracle at http://%s/oracle?iv=<hex>&ciphertext=<hex>\n", addr)
+
+	http.Handle("/oracle", paddingoracle.NewHandler(oracle.Valid))
+	return http.ListenAndServe(addr, nil)
+}
+
+// demo attacks the local oracle with the message encrypted under its random key
+func demo(message []byte, forge string) error {
+	oracle, err := newOracle()
+	if err != nil {
+		return err
+	}
+	if forge != "" {
+		return attack(oracle.Valid, nil, nil, forge)
+	}
+	iv, cipherText, err := oracle.Encrypt(message)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("IV:          %x\n", iv)
+	fmt.Printf("Cipher text: %x\n", cipherText)
+	return attack(oracle.Valid, iv, cipherText, forge)
+}
+
+// attack decrypts the cipher text or forges the encryption of given plain text and prints query statistics
+func attack(oracle paddingoracle.Oracle, iv, cipherText []byte, forge string) error {
+	attacker := paddingoracle.NewAttacker(oracle)
+	if forge != "" {
+		forgedIV, forged, err := attacker.Encrypt([]byte(forge))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Forged IV:          %x\n", forgedIV)
+		fmt.Printf("Forged cipher text: %x\n", forged)
+	} else {
+		plainText, err := attacker.Decrypt(iv, cipherText)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Plain text:  %s\n", plainText)
+	}
+
+	stats := attacker.Stats()
+	fmt.Printf("Queries:     %d (%d blocks, %.1f per byte)\n", stats.Queries, stats.Blocks, stats.QueriesPerByte())
+	return nil
+}
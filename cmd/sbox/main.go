@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mas9612/cryptostudy/pkg/sbox"
+)
+
+func main() {
+	input := flag.String("input", "", "File of S-box entries in hexadecimal separated by white spaces or commas (default AES S-box)")
+	inverse := flag.Bool("inverse", false, "Analyze the inverse S-box of AES instead of the S-box")
+	ddt := flag.String("ddt", "", "Export difference distribution table to given CSV file")
+	lat := flag.String("lat", "", "Export linear approximation table to given CSV file")
+	help := flag.Bool("help", false, "Print help and exit")
+	flag.Parse()
+	if *help {
+		flag.Usage()
+		os.Exit(0)
+	}
+
+	s, err := load(*input, *inverse)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	summary := s.Analyze()
+	fmt.Printf("Size:                    %d bit\n", summary.Bits)
+	fmt.Printf("Permutation:             %v\n", summary.Permutation)
+	fmt.Printf("Differential uniformity: %d (max differential probability %d/%d)\n", summary.DifferentialUniformity, summary.DifferentialUniformity, 1<<uint(summary.Bits))
+	fmt.Printf("Nonlinearity:            %d (max linear bias %d/%d)\n", summary.Nonlinearity, summary.MaxLinearBias, 1<<uint(summary.Bits))
+	fmt.Printf("Algebraic degree:        %d\n", summary.AlgebraicDegree)
+	fmt.Printf("Fixed points:            %v\n", summary.FixedPoints)
+	if summary.Permutation {
+		fmt.Printf("Cycle lengths:           %v\n", summary.CycleLengths)
+	}
+
+	if *ddt != "" {
+		if err := export(*ddt, s.DDT()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if *lat != "" {
+		if err := export(*lat, s.LAT()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+}
+
+// load returns S-box read from given file or the AES S-box
+func load(input string, inverse bool) (*sbox.SBox, error) {
+	if input == "" {
+		if inverse {
+			return sbox.AESInverse(), nil
+		}
+		return sbox.AES(), nil
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return sbox.Parse(f)
+}
+
+// export writes given table to the file as CSV
+func export(filename string, table [][]int) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := sbox.WriteCSV(f, table); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
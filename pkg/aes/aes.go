@@ -1,158 +1,343 @@
 package aes
 
 import (
-	"fmt"
-	"log"
+	"crypto/cipher"
+	"errors"
 )
 
-// Cipher encrypts plain text
-func Cipher(in []byte, key []byte, mode int, iv []byte) []byte {
+var (
+	// ErrKeySize is returned when given key is not 128, 192 or 256 bit
+	ErrKeySize = errors.New("AES key length must be one of 128, 192, 256 bit")
+	// ErrBlockSize is returned when given block size is not supported by Rijndael or the encryption mode
+	ErrBlockSize = errors.New("invalid block size")
+	// ErrIVSize is returned when given IV is not same as block size
+	ErrIVSize = errors.New("IV must be same as block size")
+	// ErrInvalidMode is returned when given encryption mode is unknown
+	ErrInvalidMode = errors.New("invalid encryption mode")
+	// ErrInvalidPadding is returned when padding of decrypted text is malformed
+	ErrInvalidPadding = errors.New("invalid padding")
+	// ErrPlaintextLength is returned when given plain text is too short or too long for the encryption mode
+	ErrPlaintextLength = errors.New("invalid plain text length for the encryption mode")
+	// ErrCiphertextLength is returned when given cipher text has invalid length for the encryption mode
+	ErrCiphertextLength = errors.New("invalid cipher text length for the encryption mode")
+	// ErrInvalidImplementation is returned when given implementation is unknown
+	ErrInvalidImplementation = errors.New("invalid implementation")
+	// ErrRounds is returned when given number of rounds is not positive
+	ErrRounds = errors.New("number of rounds must be positive")
+	// ErrSegmentSize is returned when given segment size is not supported by CFB mode
+	ErrSegmentSize = errors.New("invalid segment size for CFB mode")
+	// ErrKeyPosition is returned when given round key window is out of the expanded key
+	ErrKeyPosition = errors.New("round key window is out of the expanded key")
+)
+
+// Implementation represents the implementation of AES block function
+type Implementation int
+
+const (
+	// ImplementationReference is the step-by-step implementation following FIPS-197
+	ImplementationReference Implementation = iota
+	// ImplementationTTable is the optimized implementation with precomputed 32 bit T-tables
+	ImplementationTTable
+	// ImplementationBitsliced is the constant-time implementation with bitsliced S-box.
+	// It doesn't use secret-dependent table lookups or branches.
+	ImplementationBitsliced
+)
+
+// Block is an AES (Rijndael) block cipher instance with an expanded key.
+// It implements crypto/cipher.Block and is safe for concurrent use.
+type Block struct {
+	nk          int
+	nb          int
+	nr          int
+	impl        Implementation
+	expandedKey []byte
+	eqInvKey    []byte   // decryption key schedule of the equivalent inverse cipher
+	encKey      []uint32 // round keys for T-table implementation
+	decKey      []uint32 // round keys for T-table implementation of the equivalent inverse cipher
+	variant     *variant // Rijndael variant with its own field and S-box. nil selects AES.
+	tracer      Tracer
+}
+
+var _ cipher.Block = (*Block)(nil)
+
+// NewCipher creates a new Block from given key with the reference implementation.
+// The key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+func NewCipher(key []byte) (*Block, error) {
+	return NewCipherWithImplementation(key, ImplementationReference)
+}
+
+// NewCipherWithImplementation creates a new Block from given key with given implementation
+func NewCipherWithImplementation(key []byte, impl Implementation) (*Block, error) {
+	return NewRijndaelWithImplementation(key, BlockSize128*BytesOfWords, impl)
+}
+
+// NewRijndael creates a new Rijndael Block from given key and block size with the reference implementation.
+// Both of the key and the block size must be 16, 24 or 32 bytes.
+// Rijndael with 16 bytes block is the same as AES.
+func NewRijndael(key []byte, blockSize int) (*Block, error) {
+	return NewRijndaelWithImplementation(key, blockSize, ImplementationReference)
+}
+
+// NewRijndaelWithImplementation creates a new Rijndael Block from given key and block size with given implementation
+func NewRijndaelWithImplementation(key []byte, blockSize int, impl Implementation) (*Block, error) {
+	return newBlock(key, blockSize, 0, impl, nil)
+}
+
+// NewCipherWithRounds creates a new AES Block with given number of rounds instead of the standard Nr.
+// The last round doesn't have MixColumns like the full cipher.
+// It's intended for cryptanalysis of reduced-round AES.
+func NewCipherWithRounds(key []byte, rounds int) (*Block, error) {
+	if rounds < 1 {
+		return nil, ErrRounds
+	}
+	return newBlock(key, BlockSize128*BytesOfWords, rounds, ImplementationReference, nil)
+}
+
+// newBlock creates a new Block. rounds overrides Nr unless it's 0, and v overrides AES unless it's nil.
+func newBlock(key []byte, blockSize, rounds int, impl Implementation, v *variant) (*Block, error) {
+	b := &Block{
+		impl:    impl,
+		variant: v,
+	}
 	switch len(key) {
 	case 16:
-		Nk = KeyLength128
-		Nb = BlockSize128
-		Nr = NumOfRounds128
+		b.nk = KeyLength128
 	case 24:
-		Nk = KeyLength192
-		Nb = BlockSize192
-		Nr = NumOfRounds192
+		b.nk = KeyLength192
 	case 32:
-		Nk = KeyLength256
-		Nb = BlockSize256
-		Nr = NumOfRounds256
+		b.nk = KeyLength256
+	default:
+		return nil, ErrKeySize
+	}
+	switch blockSize {
+	case 16, 24, 32:
+		b.nb = blockSize / BytesOfWords
+	default:
+		return nil, ErrBlockSize
+	}
+	// Nr = max(Nk, Nb) + 6
+	b.nr = b.nk + 6
+	if b.nb > b.nk {
+		b.nr = b.nb + 6
+	}
+	if rounds != 0 {
+		b.nr = rounds
+	}
+
+	switch impl {
+	case ImplementationReference, ImplementationTTable, ImplementationBitsliced:
 	default:
-		log.Fatalln("AES key length must be one of 128, 192, 256 bit")
+		return nil, ErrInvalidImplementation
 	}
 
-	expandedKey := make([]byte, BytesOfWords*Nb*(Nr+1))
-	keyExpansion(key, expandedKey)
+	b.expandedKey = make([]byte, BytesOfWords*b.nb*(b.nr+1))
+	b.keyExpansion(key)
+	b.eqInvKeyExpansion()
+	if impl == ImplementationTTable {
+		b.expandTTableKey()
+	}
+	return b, nil
+}
 
-	numOfBlocks := len(in) / (Nb * BytesOfWords)
-	if len(in)%(Nb*BytesOfWords) != 0 {
+// BlockSize returns the block size in bytes
+func (b *Block) BlockSize() int {
+	return b.nb * BytesOfWords
+}
+
+// Rounds returns the number of rounds
+func (b *Block) Rounds() int {
+	return b.nr
+}
+
+// Encrypt encrypts the first block in src into dst
+func (b *Block) Encrypt(dst, src []byte) {
+	state := make([]byte, b.BlockSize())
+	copy(state, src[:b.BlockSize()])
+	b.blockCipher(state)
+	copy(dst, state)
+}
+
+// Decrypt decrypts the first block in src into dst
+func (b *Block) Decrypt(dst, src []byte) {
+	state := make([]byte, b.BlockSize())
+	copy(state, src[:b.BlockSize()])
+	b.invBlockCipher(state)
+	copy(dst, state)
+}
+
+// EqInvCipher decrypts the first block in src into dst with the equivalent inverse cipher.
+// The result is the same as Decrypt.
+func (b *Block) EqInvCipher(dst, src []byte) {
+	state := make([]byte, b.BlockSize())
+	copy(state, src[:b.BlockSize()])
+	b.eqInvBlockCipher(state)
+	copy(dst, state)
+}
+
+// numOfBlocks returns the number of blocks needed to hold n bytes
+func (b *Block) numOfBlocks(n int) int {
+	numOfBlocks := n / b.BlockSize()
+	if n%b.BlockSize() != 0 {
 		numOfBlocks++
 	}
+	return numOfBlocks
+}
+
+// roundKey returns the round key used in given round
+func (b *Block) roundKey(round int) []byte {
+	return b.expandedKey[round*b.BlockSize() : (round+1)*b.BlockSize()]
+}
+
+// eqInvRoundKey returns the round key used in given round of the equivalent inverse cipher
+func (b *Block) eqInvRoundKey(round int) []byte {
+	return b.eqInvKey[round*b.BlockSize() : (round+1)*b.BlockSize()]
+}
+
+// Cipher encrypts plain text.
+// Padding is used only in ECB and CBC modes; nil selects PKCS7Padding.
+func Cipher(in []byte, key []byte, mode int, iv []byte, padding Padding) ([]byte, error) {
+	if mode == ModeXTS {
+		// XTS uses double-length key
+		return XTSCipher(in, key, iv)
+	}
 
-	var out []byte
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return CipherWithBlock(b, in, mode, iv, padding)
+}
+
+// CipherWithBlock encrypts plain text with given Block, e.g. the Block which has its own tracer.
+// ModeXTS is not supported because it uses two Blocks.
+func CipherWithBlock(b *Block, in []byte, mode int, iv []byte, padding Padding) ([]byte, error) {
 	switch mode {
 	case ModeECB:
-		out = ECBCipher(in, expandedKey, numOfBlocks)
+		return ECBCipher(b, in, padding)
 	case ModeCBC:
-		out = CBCCipher(in, expandedKey, iv, numOfBlocks)
+		return CBCCipher(b, in, iv, padding)
 	case ModeCFB:
-		out = CFBCipher(in, expandedKey, iv, numOfBlocks)
+		return CFBCipher(b, in, iv)
+	case ModeCFB8:
+		return CFBSegmentCipher(b, in, iv, 8)
+	case ModeCFB1:
+		return CFBSegmentCipher(b, in, iv, 1)
 	case ModeOFB:
-		out = OFBCipher(in, expandedKey, iv, numOfBlocks)
+		return OFBCipher(b, in, iv)
 	case ModeCTR:
-		out = CTRCipher(in, expandedKey, iv, numOfBlocks)
+		return CTRCipher(b, in, iv)
 	case ModeCBCCTS:
-		out = CBCCTSCipher(in, expandedKey, iv, numOfBlocks)
-	default:
-		log.Fatalln("Invalid encryption mode")
+		return CBCCTSCipher(b, in, iv)
+	case ModeGCM:
+		return GCMCipher(b, in, iv)
+	case ModeCCM:
+		return CCMCipher(b, in, iv)
 	}
-	return out
+	return nil, ErrInvalidMode
 }
 
-// InvCipher decrypt given cipher text
-func InvCipher(in, key []byte, mode int, iv []byte) []byte {
-	switch len(key) {
-	case 16:
-		Nk = KeyLength128
-		Nb = BlockSize128
-		Nr = NumOfRounds128
-	case 24:
-		Nk = KeyLength192
-		Nb = BlockSize192
-		Nr = NumOfRounds192
-	case 32:
-		Nk = KeyLength256
-		Nb = BlockSize256
-		Nr = NumOfRounds256
-	default:
-		log.Fatalln("AES key length must be one of 128, 192, 256 bit")
+// InvCipher decrypt given cipher text.
+// Padding is used only in ECB and CBC modes; nil selects PKCS7Padding.
+func InvCipher(in, key []byte, mode int, iv []byte, padding Padding) ([]byte, error) {
+	if mode == ModeXTS {
+		// XTS uses double-length key
+		return XTSInvCipher(in, key, iv)
 	}
 
-	expandedKey := make([]byte, BytesOfWords*Nb*(Nr+1))
-	keyExpansion(key, expandedKey)
-
-	numOfBlocks := len(in) / (Nb * BytesOfWords)
-	if len(in)%(Nb*BytesOfWords) != 0 {
-		numOfBlocks++
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
 	}
+	return InvCipherWithBlock(b, in, mode, iv, padding)
+}
 
-	var out []byte
+// InvCipherWithBlock decrypts cipher text with given Block.
+// ModeXTS is not supported because it uses two Blocks.
+func InvCipherWithBlock(b *Block, in []byte, mode int, iv []byte, padding Padding) ([]byte, error) {
 	switch mode {
 	case ModeECB:
-		out = ECBInvCipher(in, expandedKey, numOfBlocks)
+		return ECBInvCipher(b, in, padding)
 	case ModeCBC:
-		out = CBCInvCipher(in, expandedKey, iv, numOfBlocks)
+		return CBCInvCipher(b, in, iv, padding)
 	case ModeCFB:
-		out = CFBInvCipher(in, expandedKey, iv, numOfBlocks)
+		return CFBInvCipher(b, in, iv)
+	case ModeCFB8:
+		return CFBSegmentInvCipher(b, in, iv, 8)
+	case ModeCFB1:
+		return CFBSegmentInvCipher(b, in, iv, 1)
 	case ModeOFB:
-		out = OFBInvCipher(in, expandedKey, iv, numOfBlocks)
+		return OFBInvCipher(b, in, iv)
 	case ModeCTR:
-		out = CTRInvCipher(in, expandedKey, iv, numOfBlocks)
+		return CTRInvCipher(b, in, iv)
 	case ModeCBCCTS:
-		out = CBCCTSInvCipher(in, expandedKey, iv, numOfBlocks)
-	default:
-		log.Fatalln("Invalid encryption mode")
+		return CBCCTSInvCipher(b, in, iv)
+	case ModeGCM:
+		return GCMInvCipher(b, in, iv)
+	case ModeCCM:
+		return CCMInvCipher(b, in, iv)
 	}
-	return out
+	return nil, ErrInvalidMode
 }
 
-func blockCipher(state, key []byte) {
-	round := 0
-	if round == PrintNRound {
-		fmt.Printf("[Round %d]\n", round)
+func (b *Block) blockCipher(state []byte) {
+	if b.impl == ImplementationTTable {
+		b.tTableCipher(state)
+		return
 	}
-	AddRoundKey(state, key[:Nb*BytesOfWords])
-	printRoundBytes(state, round, "AddRoundKey")
-
-	for round = 1; round <= Nr; round++ {
-		if round == PrintNRound {
-			fmt.Printf("[Round %d]\n", round)
-		}
-		SubBytes(state)
-		printRoundBytes(state, round, "SubBytes")
-
-		ShiftRows(state)
-		printRoundBytes(state, round, "ShiftRows")
 
-		if round < Nr {
-			MixColumns(state)
-			printRoundBytes(state, round, "MixColumns")
+	tracer := b.tracer
+	round := 0
+	traceStep(tracer, round, StepAddRoundKey, false, state, b.roundKey(round), func() { AddRoundKey(state, b.roundKey(round)) })
 
+	for round = 1; round <= b.nr; round++ {
+		traceStep(tracer, round, StepSubBytes, false, state, nil, func() { b.subBytes(state) })
+		traceStep(tracer, round, StepShiftRows, false, state, nil, func() { ShiftRows(state) })
+		if round < b.nr {
+			traceStep(tracer, round, StepMixColumns, false, state, nil, func() { b.mixColumns(state) })
 		}
-		AddRoundKey(state, key[round*Nb*BytesOfWords:(round+1)*Nb*BytesOfWords])
-		printRoundBytes(state, round, "AddRoundKey")
-
+		traceStep(tracer, round, StepAddRoundKey, false, state, b.roundKey(round), func() { AddRoundKey(state, b.roundKey(round)) })
 	}
 }
 
-func invBlockCipher(state, key []byte) {
-	round := Nr
-	if round == PrintNRound {
-		fmt.Printf("[Round %d]\n", round)
+func (b *Block) invBlockCipher(state []byte) {
+	if b.impl == ImplementationTTable {
+		b.tTableInvCipher(state)
+		return
 	}
-	AddRoundKey(state, key[round*Nb*BytesOfWords:(round+1)*Nb*BytesOfWords])
-	printRoundBytes(state, round, "AddRoundKey")
 
-	for round = Nr - 1; round >= 0; round-- {
-		if round == PrintNRound {
-			fmt.Printf("[Round %d]\n", round)
+	tracer := b.tracer
+	round := b.nr
+	traceStep(tracer, round, StepAddRoundKey, true, state, b.roundKey(round), func() { AddRoundKey(state, b.roundKey(round)) })
+
+	for round = b.nr - 1; round >= 0; round-- {
+		traceStep(tracer, round, StepInvShiftRows, true, state, nil, func() { InvShiftRows(state) })
+		traceStep(tracer, round, StepInvSubBytes, true, state, nil, func() { b.invSubBytes(state) })
+		traceStep(tracer, round, StepAddRoundKey, true, state, b.roundKey(round), func() { AddRoundKey(state, b.roundKey(round)) })
+		if round > 0 {
+			traceStep(tracer, round, StepInvMixColumns, true, state, nil, func() { b.invMixColumns(state) })
 		}
-		InvShiftRows(state)
-		printRoundBytes(state, round, "InvShiftRows")
+	}
+}
 
-		InvSubBytes(state)
-		printRoundBytes(state, round, "InvSubBytes")
+// eqInvBlockCipher decrypts given state with the equivalent inverse cipher defined in FIPS-197 Section 5.3.5.
+// Steps are in the same order as blockCipher by using the decryption key schedule.
+func (b *Block) eqInvBlockCipher(state []byte) {
+	if b.impl == ImplementationTTable {
+		// T-table implementation is always the equivalent inverse cipher
+		b.tTableInvCipher(state)
+		return
+	}
 
-		AddRoundKey(state, key[round*Nb*BytesOfWords:(round+1)*Nb*BytesOfWords])
-		printRoundBytes(state, round, "AddRoundKey")
+	tracer := b.tracer
+	round := b.nr
+	traceStep(tracer, round, StepAddRoundKey, true, state, b.eqInvRoundKey(round), func() { AddRoundKey(state, b.eqInvRoundKey(round)) })
 
+	for round = b.nr - 1; round >= 0; round-- {
+		traceStep(tracer, round, StepInvSubBytes, true, state, nil, func() { b.invSubBytes(state) })
+		traceStep(tracer, round, StepInvShiftRows, true, state, nil, func() { InvShiftRows(state) })
 		if round > 0 {
-			InvMixColumns(state)
-			printRoundBytes(state, round, "InvMixColumns")
-
+			traceStep(tracer, round, StepInvMixColumns, true, state, nil, func() { b.invMixColumns(state) })
 		}
+		traceStep(tracer, round, StepAddRoundKey, true, state, b.eqInvRoundKey(round), func() { AddRoundKey(state, b.eqInvRoundKey(round)) })
 	}
 }
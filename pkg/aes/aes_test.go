@@ -2,15 +2,15 @@ package aes
 
 import (
 	"bytes"
-	"log"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
 	"testing"
+	"testing/iotest"
 )
 
 func TestCipher(t *testing.T) {
-	Nb = BlockSize128
-	Nr = NumOfRounds128
-	Nk = KeyLength128
-
 	inputs := [][]byte{
 		// ECB encryption
 		[]byte{
@@ -152,7 +152,10 @@ func TestCipher(t *testing.T) {
 	}
 
 	for i, input := range inputs {
-		cipherText := Cipher(input, keys[i], modes[i], ivs[i])
+		cipherText, err := Cipher(input, keys[i], modes[i], ivs[i], NoPadding{})
+		if err != nil {
+			t.Fatalf("[TestCipher] case %d failed: %v", i, err)
+		}
 		if !bytes.Equal(cipherText, expected[i]) {
 			t.Errorf("[TestCipher] case %d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", i, PrintableBytes(cipherText), PrintableBytes(expected[i]))
 		}
@@ -160,10 +163,6 @@ func TestCipher(t *testing.T) {
 }
 
 func TestInvCipher(t *testing.T) {
-	Nb = BlockSize128
-	Nr = NumOfRounds128
-	Nk = KeyLength128
-
 	inputs := [][]byte{
 		// ECB
 		[]byte{
@@ -305,7 +304,10 @@ func TestInvCipher(t *testing.T) {
 	}
 
 	for i, input := range inputs {
-		plainText := InvCipher(input, keys[i], modes[i], ivs[i])
+		plainText, err := InvCipher(input, keys[i], modes[i], ivs[i], NoPadding{})
+		if err != nil {
+			t.Fatalf("[TestInvCipher] case %d failed: %v", i, err)
+		}
 		if !bytes.Equal(plainText, expected[i]) {
 			t.Errorf("[TestInvCipher] case %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", i, PrintableBytes(plainText), PrintableBytes(expected[i]))
 		}
@@ -346,28 +348,19 @@ func TestBlockCipher(t *testing.T) {
 			0xf3, 0xee, 0xd1, 0xbd, 0xb5, 0xd2, 0xa0, 0x3c, 0x06, 0x4b, 0x5a, 0x7e, 0x3d, 0xb1, 0x81, 0xf8,
 		},
 	}
-	for i, input := range inputs {
-		switch len(keys[i]) {
-		case 16:
-			Nk = KeyLength128
-			Nb = BlockSize128
-			Nr = NumOfRounds128
-		case 24:
-			Nk = KeyLength192
-			Nb = BlockSize192
-			Nr = NumOfRounds192
-		case 32:
-			Nk = KeyLength256
-			Nb = BlockSize256
-			Nr = NumOfRounds256
-		default:
-			log.Fatalln("AES key length must be one of 128, 192, 256 bit")
-		}
-		expandedKey := make([]byte, BytesOfWords*Nb*(Nr+1))
-		keyExpansion(keys[i], expandedKey)
-		blockCipher(input, expandedKey)
-		if !bytes.Equal(input, expected[i]) {
-			t.Errorf("[TestBlockCipher] case %d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", i, PrintableBytes(input), PrintableBytes(expected[i]))
+	implementations := []Implementation{ImplementationReference, ImplementationTTable, ImplementationBitsliced}
+	for _, impl := range implementations {
+		for i, input := range inputs {
+			b, err := NewCipherWithImplementation(keys[i], impl)
+			if err != nil {
+				t.Fatalf("[TestBlockCipher] case %d-%d failed: %v", impl, i, err)
+			}
+			state := make([]byte, len(input))
+			copy(state, input)
+			b.blockCipher(state)
+			if !bytes.Equal(state, expected[i]) {
+				t.Errorf("[TestBlockCipher] case %d-%d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", impl, i, PrintableBytes(state), PrintableBytes(expected[i]))
+			}
 		}
 	}
 }
@@ -406,28 +399,2899 @@ func TestInvBlockCipher(t *testing.T) {
 			0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
 		},
 	}
-	for i, input := range inputs {
-		switch len(keys[i]) {
-		case 16:
-			Nk = KeyLength128
-			Nb = BlockSize128
-			Nr = NumOfRounds128
-		case 24:
-			Nk = KeyLength192
-			Nb = BlockSize192
-			Nr = NumOfRounds192
-		case 32:
-			Nk = KeyLength256
-			Nb = BlockSize256
-			Nr = NumOfRounds256
-		default:
-			log.Fatalln("AES key length must be one of 128, 192, 256 bit")
-		}
-		expandedKey := make([]byte, BytesOfWords*Nb*(Nr+1))
-		keyExpansion(keys[i], expandedKey)
-		invBlockCipher(input, expandedKey)
-		if !bytes.Equal(input, expected[i]) {
-			t.Errorf("[TestInvBlockCipher] case %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", i, PrintableBytes(input), PrintableBytes(expected[i]))
+	implementations := []Implementation{ImplementationReference, ImplementationTTable, ImplementationBitsliced}
+	for _, impl := range implementations {
+		for i, input := range inputs {
+			b, err := NewCipherWithImplementation(keys[i], impl)
+			if err != nil {
+				t.Fatalf("[TestInvBlockCipher] case %d-%d failed: %v", impl, i, err)
+			}
+			state := make([]byte, len(input))
+			copy(state, input)
+			b.invBlockCipher(state)
+			if !bytes.Equal(state, expected[i]) {
+				t.Errorf("[TestInvBlockCipher] case %d-%d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", impl, i, PrintableBytes(state), PrintableBytes(expected[i]))
+			}
+
+			// the equivalent inverse cipher
+			copy(state, input)
+			b.eqInvBlockCipher(state)
+			if !bytes.Equal(state, expected[i]) {
+				t.Errorf("[TestInvBlockCipher] case %d-%d failed: equivalent inverse cipher plainText != expected :\nplain:\t\t%s\nexpected:\t%s", impl, i, PrintableBytes(state), PrintableBytes(expected[i]))
+			}
+		}
+	}
+}
+
+func TestCipherErrors(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	iv := make([]byte, 16)
+
+	cases := []struct {
+		decrypt  bool
+		in       []byte
+		key      []byte
+		mode     int
+		iv       []byte
+		expected error
+	}{
+		{false, make([]byte, 16), key[:15], ModeECB, nil, ErrKeySize},
+		{true, make([]byte, 16), append(key, 0x00), ModeECB, nil, ErrKeySize},
+		{false, make([]byte, 16), key, 0, nil, ErrInvalidMode},
+		{true, make([]byte, 16), key, 0, nil, ErrInvalidMode},
+		{false, make([]byte, 16), key, ModeCBC, iv[:8], ErrIVSize},
+		{true, make([]byte, 16), key, ModeCBC, nil, ErrIVSize},
+		{false, make([]byte, 16), key, ModeCFB, iv[:15], ErrIVSize},
+		{false, make([]byte, 16), key, ModeOFB, nil, ErrIVSize},
+		{false, make([]byte, 16), key, ModeCTR, iv[:8], ErrIVSize},
+		{false, make([]byte, 17), key, ModeCBCCTS, nil, ErrIVSize},
+		{true, make([]byte, 15), key, ModeECB, nil, ErrCiphertextLength},
+		{true, make([]byte, 0), key, ModeECB, nil, ErrCiphertextLength},
+		{true, make([]byte, 33), key, ModeCBC, iv, ErrCiphertextLength},
+		{true, make([]byte, 16), key, ModeCBCCTS, iv, ErrCiphertextLength},
+		{false, make([]byte, 16), key, ModeCBCCTS, iv, ErrPlaintextLength},
+	}
+
+	for i, c := range cases {
+		var err error
+		if c.decrypt {
+			_, err = InvCipher(c.in, c.key, c.mode, c.iv, nil)
+		} else {
+			_, err = Cipher(c.in, c.key, c.mode, c.iv, nil)
+		}
+		if !errors.Is(err, c.expected) {
+			t.Errorf("[TestCipherErrors] case %d failed: err != expected : '%v' != '%v'", i, err, c.expected)
+		}
+	}
+}
+
+func TestInvalidPadding(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	// last block ends with 0x03 but the preceding padding bytes are not 0x03
+	plainText := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x02, 0x03}
+
+	cipherText, err := Cipher(plainText, key, ModeECB, nil, NoPadding{})
+	if err != nil {
+		t.Fatalf("[TestInvalidPadding] failed: %v", err)
+	}
+	if _, err := InvCipher(cipherText, key, ModeECB, nil, PKCS7Padding{}); !errors.Is(err, ErrInvalidPadding) {
+		t.Errorf("[TestInvalidPadding] failed: err != expected : '%v' != '%v'", err, ErrInvalidPadding)
+	}
+
+	// block-aligned plain text ending with padding-like byte must survive round trip
+	cipherText, err = Cipher(plainText, key, ModeECB, nil, nil)
+	if err != nil {
+		t.Fatalf("[TestInvalidPadding] failed: %v", err)
+	}
+	if len(cipherText) != 32 {
+		t.Errorf("[TestInvalidPadding] failed: len(cipherText) != expected : %d != %d", len(cipherText), 32)
+	}
+	decrypted, err := InvCipher(cipherText, key, ModeECB, nil, nil)
+	if err != nil {
+		t.Errorf("[TestInvalidPadding] failed: %v", err)
+	} else if !bytes.Equal(decrypted, plainText) {
+		t.Errorf("[TestInvalidPadding] failed: decrypted != expected :\ndecrypted:\t%s\nexpected:\t%s", PrintableBytes(decrypted), PrintableBytes(plainText))
+	}
+}
+
+func TestPadding(t *testing.T) {
+	cases := []struct {
+		padding  Padding
+		in       []byte
+		expected []byte
+	}{
+		{PKCS7Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x03, 0x03, 0x03}},
+		{PKCS7Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08}},
+		{ANSIX923Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x00, 0x00, 0x03}},
+		{ISO78164Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x80, 0x00, 0x00}},
+		{ISO78164Padding{}, []byte{}, []byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{ZeroPadding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x00, 0x00, 0x00}},
+		{ZeroPadding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd}},
+		{NoPadding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd}},
+	}
+
+	for i, c := range cases {
+		padded, err := c.padding.Pad(c.in, 8)
+		if err != nil {
+			t.Errorf("[TestPadding] case %d failed: %v", i, err)
+		} else if !bytes.Equal(padded, c.expected) {
+			t.Errorf("[TestPadding] case %d failed: padded != expected :\npadded:\t\t%s\nexpected:\t%s", i, PrintableBytes(padded), PrintableBytes(c.expected))
+		}
+
+		unpadded, err := c.padding.Unpad(c.expected, 8)
+		if err != nil {
+			t.Errorf("[TestPadding] case %d failed: %v", i, err)
+		} else if !bytes.Equal(unpadded, c.in) {
+			t.Errorf("[TestPadding] case %d failed: unpadded != expected :\nunpadded:\t%s\nexpected:\t%s", i, PrintableBytes(unpadded), PrintableBytes(c.in))
+		}
+	}
+
+	// ISO 10126 padding is random except the last byte
+	padded, err := ISO10126Padding{}.Pad([]byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd}, 8)
+	if err != nil {
+		t.Fatalf("[TestPadding] ISO 10126 failed: %v", err)
+	}
+	if len(padded) != 8 || padded[7] != 0x03 {
+		t.Errorf("[TestPadding] ISO 10126 failed: padded is malformed : %s", PrintableBytes(padded))
+	}
+	if unpadded, err := (ISO10126Padding{}).Unpad(padded, 8); err != nil || !bytes.Equal(unpadded, padded[:5]) {
+		t.Errorf("[TestPadding] ISO 10126 failed: unpadded != expected : %s, %v", PrintableBytes(unpadded), err)
+	}
+
+	if _, err := (NoPadding{}).Pad([]byte{0xdd}, 8); !errors.Is(err, ErrPlaintextLength) {
+		t.Errorf("[TestPadding] failed: err != expected : '%v' != '%v'", err, ErrPlaintextLength)
+	}
+
+	invalids := []struct {
+		padding Padding
+		in      []byte
+	}{
+		{PKCS7Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x02, 0x03, 0x03}},
+		{PKCS7Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x00}},
+		{PKCS7Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x09}},
+		{PKCS7Padding{}, []byte{}},
+		{ANSIX923Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x00, 0x01, 0x03}},
+		{ISO78164Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x00, 0x00, 0x00}},
+		{ISO78164Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x80, 0x00, 0x01}},
+		{ISO10126Padding{}, []byte{0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0xdd, 0x10}},
+	}
+	for i, c := range invalids {
+		if _, err := c.padding.Unpad(c.in, 8); !errors.Is(err, ErrInvalidPadding) {
+			t.Errorf("[TestPadding] invalid case %d failed: err != expected : '%v' != '%v'", i, err, ErrInvalidPadding)
+		}
+	}
+}
+
+func TestCBCCTSBlockAligned(t *testing.T) {
+	// test vector of CBC with CTS is defined in rfc3962 page 11.
+	key := []byte{0x63, 0x68, 0x69, 0x63, 0x6b, 0x65, 0x6e, 0x20, 0x74, 0x65, 0x72, 0x69, 0x79, 0x61, 0x6b, 0x69}
+	iv := make([]byte, 16)
+	plainText := []byte{
+		0x49, 0x20, 0x77, 0x6f, 0x75, 0x6c, 0x64, 0x20, 0x6c, 0x69, 0x6b, 0x65, 0x20, 0x74, 0x68, 0x65,
+		0x20, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x6c, 0x20, 0x47, 0x61, 0x75, 0x27, 0x73, 0x20, 0x43,
+	}
+	expected := []byte{
+		0x39, 0x31, 0x25, 0x23, 0xa7, 0x86, 0x62, 0xd5, 0xbe, 0x7f, 0xcb, 0xcc, 0x98, 0xeb, 0xf5, 0xa8,
+		0x97, 0x68, 0x72, 0x68, 0xd6, 0xec, 0xcc, 0xc0, 0xc0, 0x7b, 0x25, 0xe2, 0x5e, 0xcf, 0xe5, 0x84,
+	}
+
+	cipherText, err := Cipher(plainText, key, ModeCBCCTS, iv, nil)
+	if err != nil {
+		t.Fatalf("[TestCBCCTSBlockAligned] failed: %v", err)
+	}
+	if !bytes.Equal(cipherText, expected) {
+		t.Errorf("[TestCBCCTSBlockAligned] failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", PrintableBytes(cipherText), PrintableBytes(expected))
+	}
+	decrypted, err := InvCipher(cipherText, key, ModeCBCCTS, iv, nil)
+	if err != nil {
+		t.Fatalf("[TestCBCCTSBlockAligned] failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, plainText) {
+		t.Errorf("[TestCBCCTSBlockAligned] failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", PrintableBytes(decrypted), PrintableBytes(plainText))
+	}
+}
+
+func TestBlockConcurrent(t *testing.T) {
+	// test vectors are defined in FIPS-197 Appendix C.1 and C.3
+	plainText := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	keys := [][]byte{
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+			0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+		},
+	}
+	expected := [][]byte{
+		[]byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a},
+		[]byte{0x8e, 0xa2, 0xb7, 0xca, 0x51, 0x67, 0x45, 0xbf, 0xea, 0xfc, 0x49, 0x90, 0x4b, 0x49, 0x60, 0x89},
+	}
+
+	blocks := make([]*Block, len(keys))
+	for i, key := range keys {
+		b, err := NewCipher(key)
+		if err != nil {
+			t.Fatalf("[TestBlockConcurrent] case %d failed: %v", i, err)
+		}
+		blocks[i] = b
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 100*len(blocks))
+	for n := 0; n < 100; n++ {
+		for i, b := range blocks {
+			wg.Add(1)
+			go func(i int, b *Block) {
+				defer wg.Done()
+				cipherText := make([]byte, b.BlockSize())
+				b.Encrypt(cipherText, plainText)
+				if !bytes.Equal(cipherText, expected[i]) {
+					errs <- fmt.Sprintf("case %d: cipherText != expected : %s != %s", i, PrintableBytes(cipherText), PrintableBytes(expected[i]))
+					return
+				}
+				decrypted := make([]byte, b.BlockSize())
+				b.Decrypt(decrypted, cipherText)
+				if !bytes.Equal(decrypted, plainText) {
+					errs <- fmt.Sprintf("case %d: plainText != expected : %s != %s", i, PrintableBytes(decrypted), PrintableBytes(plainText))
+				}
+			}(i, b)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("[TestBlockConcurrent] %s", err)
+	}
+}
+
+func TestNewCipherKeySize(t *testing.T) {
+	for _, l := range []int{0, 8, 15, 17, 31, 33} {
+		if _, err := NewCipher(make([]byte, l)); err == nil {
+			t.Errorf("[TestNewCipherKeySize] key length %d must be rejected", l)
+		}
+	}
+}
+
+func TestGCM(t *testing.T) {
+	// test vectors are defined in "The Galois/Counter Mode of Operation (GCM)" by McGrew and Viega,
+	// which are also referenced from NIST SP 800-38D.
+	cases := []struct {
+		key        []byte
+		plain      []byte
+		additional []byte
+		nonce      []byte
+		cipher     []byte
+		tag        []byte
+	}{
+		// Test Case 1
+		{
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x58, 0xe2, 0xfc, 0xce, 0xfa, 0x7e, 0x30, 0x61, 0x36, 0x7f, 0x1d, 0x57, 0xa4, 0xe7, 0x45, 0x5a},
+		},
+		// Test Case 2
+		{
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x03, 0x88, 0xda, 0xce, 0x60, 0xb6, 0xa3, 0x92, 0xf3, 0x28, 0xc2, 0xb9, 0x71, 0xb2, 0xfe, 0x78},
+			[]byte{0xab, 0x6e, 0x47, 0xd4, 0x2c, 0xec, 0x13, 0xbd, 0xf5, 0x3a, 0x67, 0xb2, 0x12, 0x57, 0xbd, 0xdf},
+		},
+		// Test Case 3
+		{
+			[]byte{0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39, 0x1a, 0xaf, 0xd2, 0x55,
+			},
+			[]byte{},
+			[]byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad, 0xde, 0xca, 0xf8, 0x88},
+			[]byte{
+				0x42, 0x83, 0x1e, 0xc2, 0x21, 0x77, 0x74, 0x24, 0x4b, 0x72, 0x21, 0xb7, 0x84, 0xd0, 0xd4, 0x9c,
+				0xe3, 0xaa, 0x21, 0x2f, 0x2c, 0x02, 0xa4, 0xe0, 0x35, 0xc1, 0x7e, 0x23, 0x29, 0xac, 0xa1, 0x2e,
+				0x21, 0xd5, 0x14, 0xb2, 0x54, 0x66, 0x93, 0x1c, 0x7d, 0x8f, 0x6a, 0x5a, 0xac, 0x84, 0xaa, 0x05,
+				0x1b, 0xa3, 0x0b, 0x39, 0x6a, 0x0a, 0xac, 0x97, 0x3d, 0x58, 0xe0, 0x91, 0x47, 0x3f, 0x59, 0x85,
+			},
+			[]byte{0x4d, 0x5c, 0x2a, 0xf3, 0x27, 0xcd, 0x64, 0xa6, 0x2c, 0xf3, 0x5a, 0xbd, 0x2b, 0xa6, 0xfa, 0xb4},
+		},
+		// Test Case 4
+		{
+			[]byte{0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39,
+			},
+			[]byte{
+				0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef, 0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef,
+				0xab, 0xad, 0xda, 0xd2,
+			},
+			[]byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad, 0xde, 0xca, 0xf8, 0x88},
+			[]byte{
+				0x42, 0x83, 0x1e, 0xc2, 0x21, 0x77, 0x74, 0x24, 0x4b, 0x72, 0x21, 0xb7, 0x84, 0xd0, 0xd4, 0x9c,
+				0xe3, 0xaa, 0x21, 0x2f, 0x2c, 0x02, 0xa4, 0xe0, 0x35, 0xc1, 0x7e, 0x23, 0x29, 0xac, 0xa1, 0x2e,
+				0x21, 0xd5, 0x14, 0xb2, 0x54, 0x66, 0x93, 0x1c, 0x7d, 0x8f, 0x6a, 0x5a, 0xac, 0x84, 0xaa, 0x05,
+				0x1b, 0xa3, 0x0b, 0x39, 0x6a, 0x0a, 0xac, 0x97, 0x3d, 0x58, 0xe0, 0x91,
+			},
+			[]byte{0x5b, 0xc9, 0x4f, 0xbc, 0x32, 0x21, 0xa5, 0xdb, 0x94, 0xfa, 0xe9, 0x5a, 0xe7, 0x12, 0x1a, 0x47},
+		},
+		// Test Case 5 (64 bit nonce)
+		{
+			[]byte{0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39,
+			},
+			[]byte{
+				0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef, 0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef,
+				0xab, 0xad, 0xda, 0xd2,
+			},
+			[]byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad},
+			[]byte{
+				0x61, 0x35, 0x3b, 0x4c, 0x28, 0x06, 0x93, 0x4a, 0x77, 0x7f, 0xf5, 0x1f, 0xa2, 0x2a, 0x47, 0x55,
+				0x69, 0x9b, 0x2a, 0x71, 0x4f, 0xcd, 0xc6, 0xf8, 0x37, 0x66, 0xe5, 0xf9, 0x7b, 0x6c, 0x74, 0x23,
+				0x73, 0x80, 0x69, 0x00, 0xe4, 0x9f, 0x24, 0xb2, 0x2b, 0x09, 0x75, 0x44, 0xd4, 0x89, 0x6b, 0x42,
+				0x49, 0x89, 0xb5, 0xe1, 0xeb, 0xac, 0x0f, 0x07, 0xc2, 0x3f, 0x45, 0x98,
+			},
+			[]byte{0x36, 0x12, 0xd2, 0xe7, 0x9e, 0x3b, 0x07, 0x85, 0x56, 0x1b, 0xe1, 0x4a, 0xac, 0xa2, 0xfc, 0xcb},
+		},
+		// Test Case 6 (480 bit nonce)
+		{
+			[]byte{0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39,
+			},
+			[]byte{
+				0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef, 0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef,
+				0xab, 0xad, 0xda, 0xd2,
+			},
+			[]byte{
+				0x93, 0x13, 0x22, 0x5d, 0xf8, 0x84, 0x06, 0xe5, 0x55, 0x90, 0x9c, 0x5a, 0xff, 0x52, 0x69, 0xaa,
+				0x6a, 0x7a, 0x95, 0x38, 0x53, 0x4f, 0x7d, 0xa1, 0xe4, 0xc3, 0x03, 0xd2, 0xa3, 0x18, 0xa7, 0x28,
+				0xc3, 0xc0, 0xc9, 0x51, 0x56, 0x80, 0x95, 0x39, 0xfc, 0xf0, 0xe2, 0x42, 0x9a, 0x6b, 0x52, 0x54,
+				0x16, 0xae, 0xdb, 0xf5, 0xa0, 0xde, 0x6a, 0x57, 0xa6, 0x37, 0xb3, 0x9b,
+			},
+			[]byte{
+				0x8c, 0xe2, 0x49, 0x98, 0x62, 0x56, 0x15, 0xb6, 0x03, 0xa0, 0x33, 0xac, 0xa1, 0x3f, 0xb8, 0x94,
+				0xbe, 0x91, 0x12, 0xa5, 0xc3, 0xa2, 0x11, 0xa8, 0xba, 0x26, 0x2a, 0x3c, 0xca, 0x7e, 0x2c, 0xa7,
+				0x01, 0xe4, 0xa9, 0xa4, 0xfb, 0xa4, 0x3c, 0x90, 0xcc, 0xdc, 0xb2, 0x81, 0xd4, 0x8c, 0x7c, 0x6f,
+				0xd6, 0x28, 0x75, 0xd2, 0xac, 0xa4, 0x17, 0x03, 0x4c, 0x34, 0xae, 0xe5,
+			},
+			[]byte{0x61, 0x9c, 0xc5, 0xae, 0xff, 0xfe, 0x0b, 0xfa, 0x46, 0x2a, 0xf4, 0x3c, 0x16, 0x99, 0xd0, 0x50},
+		},
+		// Test Case 7
+		{
+			[]byte{
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{},
+			[]byte{},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0xcd, 0x33, 0xb2, 0x8a, 0xc7, 0x73, 0xf7, 0x4b, 0xa0, 0x0e, 0xd1, 0xf3, 0x12, 0x57, 0x24, 0x35},
+		},
+		// Test Case 8
+		{
+			[]byte{
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x98, 0xe7, 0x24, 0x7c, 0x07, 0xf0, 0xfe, 0x41, 0x1c, 0x26, 0x7e, 0x43, 0x84, 0xb0, 0xf6, 0x00},
+			[]byte{0x2f, 0xf5, 0x8d, 0x80, 0x03, 0x39, 0x27, 0xab, 0x8e, 0xf4, 0xd4, 0x58, 0x75, 0x14, 0xf0, 0xfb},
+		},
+		// Test Case 9
+		{
+			[]byte{
+				0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08,
+				0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c,
+			},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39, 0x1a, 0xaf, 0xd2, 0x55,
+			},
+			[]byte{},
+			[]byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad, 0xde, 0xca, 0xf8, 0x88},
+			[]byte{
+				0x39, 0x80, 0xca, 0x0b, 0x3c, 0x00, 0xe8, 0x41, 0xeb, 0x06, 0xfa, 0xc4, 0x87, 0x2a, 0x27, 0x57,
+				0x85, 0x9e, 0x1c, 0xea, 0xa6, 0xef, 0xd9, 0x84, 0x62, 0x85, 0x93, 0xb4, 0x0c, 0xa1, 0xe1, 0x9c,
+				0x7d, 0x77, 0x3d, 0x00, 0xc1, 0x44, 0xc5, 0x25, 0xac, 0x61, 0x9d, 0x18, 0xc8, 0x4a, 0x3f, 0x47,
+				0x18, 0xe2, 0x44, 0x8b, 0x2f, 0xe3, 0x24, 0xd9, 0xcc, 0xda, 0x27, 0x10, 0xac, 0xad, 0xe2, 0x56,
+			},
+			[]byte{0x99, 0x24, 0xa7, 0xc8, 0x58, 0x73, 0x36, 0xbf, 0xb1, 0x18, 0x02, 0x4d, 0xb8, 0x67, 0x4a, 0x14},
+		},
+		// Test Case 13
+		{
+			[]byte{
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{},
+			[]byte{},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x53, 0x0f, 0x8a, 0xfb, 0xc7, 0x45, 0x36, 0xb9, 0xa9, 0x63, 0xb4, 0xf1, 0xc4, 0xcb, 0x73, 0x8b},
+		},
+		// Test Case 14
+		{
+			[]byte{
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0xce, 0xa7, 0x40, 0x3d, 0x4d, 0x60, 0x6b, 0x6e, 0x07, 0x4e, 0xc5, 0xd3, 0xba, 0xf3, 0x9d, 0x18},
+			[]byte{0xd0, 0xd1, 0xc8, 0xa7, 0x99, 0x99, 0x6b, 0xf0, 0x26, 0x5b, 0x98, 0xb5, 0xd4, 0x8a, 0xb9, 0x19},
+		},
+		// Test Case 15
+		{
+			[]byte{
+				0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08,
+				0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08,
+			},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39, 0x1a, 0xaf, 0xd2, 0x55,
+			},
+			[]byte{},
+			[]byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad, 0xde, 0xca, 0xf8, 0x88},
+			[]byte{
+				0x52, 0x2d, 0xc1, 0xf0, 0x99, 0x56, 0x7d, 0x07, 0xf4, 0x7f, 0x37, 0xa3, 0x2a, 0x84, 0x42, 0x7d,
+				0x64, 0x3a, 0x8c, 0xdc, 0xbf, 0xe5, 0xc0, 0xc9, 0x75, 0x98, 0xa2, 0xbd, 0x25, 0x55, 0xd1, 0xaa,
+				0x8c, 0xb0, 0x8e, 0x48, 0x59, 0x0d, 0xbb, 0x3d, 0xa7, 0xb0, 0x8b, 0x10, 0x56, 0x82, 0x88, 0x38,
+				0xc5, 0xf6, 0x1e, 0x63, 0x93, 0xba, 0x7a, 0x0a, 0xbc, 0xc9, 0xf6, 0x62, 0x89, 0x80, 0x15, 0xad,
+			},
+			[]byte{0xb0, 0x94, 0xda, 0xc5, 0xd9, 0x34, 0x71, 0xbd, 0xec, 0x1a, 0x50, 0x22, 0x70, 0xe3, 0xcc, 0x6c},
+		},
+		// Test Case 16
+		{
+			[]byte{
+				0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08,
+				0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08,
+			},
+			[]byte{
+				0xd9, 0x31, 0x32, 0x25, 0xf8, 0x84, 0x06, 0xe5, 0xa5, 0x59, 0x09, 0xc5, 0xaf, 0xf5, 0x26, 0x9a,
+				0x86, 0xa7, 0xa9, 0x53, 0x15, 0x34, 0xf7, 0xda, 0x2e, 0x4c, 0x30, 0x3d, 0x8a, 0x31, 0x8a, 0x72,
+				0x1c, 0x3c, 0x0c, 0x95, 0x95, 0x68, 0x09, 0x53, 0x2f, 0xcf, 0x0e, 0x24, 0x49, 0xa6, 0xb5, 0x25,
+				0xb1, 0x6a, 0xed, 0xf5, 0xaa, 0x0d, 0xe6, 0x57, 0xba, 0x63, 0x7b, 0x39,
+			},
+			[]byte{
+				0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef, 0xfe, 0xed, 0xfa, 0xce, 0xde, 0xad, 0xbe, 0xef,
+				0xab, 0xad, 0xda, 0xd2,
+			},
+			[]byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad, 0xde, 0xca, 0xf8, 0x88},
+			[]byte{
+				0x52, 0x2d, 0xc1, 0xf0, 0x99, 0x56, 0x7d, 0x07, 0xf4, 0x7f, 0x37, 0xa3, 0x2a, 0x84, 0x42, 0x7d,
+				0x64, 0x3a, 0x8c, 0xdc, 0xbf, 0xe5, 0xc0, 0xc9, 0x75, 0x98, 0xa2, 0xbd, 0x25, 0x55, 0xd1, 0xaa,
+				0x8c, 0xb0, 0x8e, 0x48, 0x59, 0x0d, 0xbb, 0x3d, 0xa7, 0xb0, 0x8b, 0x10, 0x56, 0x82, 0x88, 0x38,
+				0xc5, 0xf6, 0x1e, 0x63, 0x93, 0xba, 0x7a, 0x0a, 0xbc, 0xc9, 0xf6, 0x62,
+			},
+			[]byte{0x76, 0xfc, 0x6e, 0xce, 0x0f, 0x4e, 0x17, 0x68, 0xcd, 0xdf, 0x88, 0x53, 0xbb, 0x2d, 0x55, 0x1b},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.key)
+		if err != nil {
+			t.Fatalf("[TestGCM] case %d failed: %v", i, err)
+		}
+		g, err := NewGCM(b)
+		if err != nil {
+			t.Fatalf("[TestGCM] case %d failed: %v", i, err)
+		}
+
+		expected := append(append([]byte{}, c.cipher...), c.tag...)
+		sealed := g.Seal(nil, c.nonce, c.plain, c.additional)
+		if !bytes.Equal(sealed, expected) {
+			t.Errorf("[TestGCM] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(expected))
+		}
+
+		opened, err := g.Open(nil, c.nonce, expected, c.additional)
+		if err != nil {
+			t.Errorf("[TestGCM] case %d failed: %v", i, err)
+		} else if !bytes.Equal(opened, c.plain) {
+			t.Errorf("[TestGCM] case %d failed: opened != expected :\nopened:\t\t%s\nexpected:\t%s", i, PrintableBytes(opened), PrintableBytes(c.plain))
+		}
+
+		// tampered tag must be rejected
+		expected[len(expected)-1] ^= 0x01
+		if _, err := g.Open(nil, c.nonce, expected, c.additional); !errors.Is(err, ErrAuthentication) {
+			t.Errorf("[TestGCM] case %d failed: err != expected : '%v' != '%v'", i, err, ErrAuthentication)
+		}
+
+		// truncated tag is the prefix of full length tag
+		for tagSize := gcmMinimumTagSize; tagSize < gcmTagSize; tagSize++ {
+			g, err := NewGCMWithTagSize(b, tagSize)
+			if err != nil {
+				t.Fatalf("[TestGCM] case %d failed: %v", i, err)
+			}
+			sealed := g.Seal(nil, c.nonce, c.plain, c.additional)
+			if !bytes.Equal(sealed, expected[:len(c.cipher)+tagSize]) {
+				t.Errorf("[TestGCM] case %d (tag size %d) failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, tagSize, PrintableBytes(sealed), PrintableBytes(expected[:len(c.cipher)+tagSize]))
+			}
+			if _, err := g.Open(nil, c.nonce, sealed, c.additional); err != nil {
+				t.Errorf("[TestGCM] case %d (tag size %d) failed: %v", i, tagSize, err)
+			}
+		}
+	}
+}
+
+func TestGCMMode(t *testing.T) {
+	key := []byte{0xfe, 0xff, 0xe9, 0x92, 0x86, 0x65, 0x73, 0x1c, 0x6d, 0x6a, 0x8f, 0x94, 0x67, 0x30, 0x83, 0x08}
+	nonce := []byte{0xca, 0xfe, 0xba, 0xbe, 0xfa, 0xce, 0xdb, 0xad, 0xde, 0xca, 0xf8, 0x88}
+	plainText := []byte("GCM mode via Cipher and InvCipher")
+
+	cipherText, err := Cipher(plainText, key, ModeGCM, nonce, nil)
+	if err != nil {
+		t.Fatalf("[TestGCMMode] failed: %v", err)
+	}
+	if len(cipherText) != len(plainText)+gcmTagSize {
+		t.Errorf("[TestGCMMode] failed: len(cipherText) != expected : %d != %d", len(cipherText), len(plainText)+gcmTagSize)
+	}
+	decrypted, err := InvCipher(cipherText, key, ModeGCM, nonce, nil)
+	if err != nil {
+		t.Fatalf("[TestGCMMode] failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, plainText) {
+		t.Errorf("[TestGCMMode] failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", PrintableBytes(decrypted), PrintableBytes(plainText))
+	}
+
+	cipherText[0] ^= 0x01
+	if _, err := InvCipher(cipherText, key, ModeGCM, nonce, nil); !errors.Is(err, ErrAuthentication) {
+		t.Errorf("[TestGCMMode] failed: err != expected : '%v' != '%v'", err, ErrAuthentication)
+	}
+	if _, err := Cipher(plainText, key, ModeGCM, nil, nil); !errors.Is(err, ErrIVSize) {
+		t.Errorf("[TestGCMMode] failed: err != expected : '%v' != '%v'", err, ErrIVSize)
+	}
+
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestGCMMode] failed: %v", err)
+	}
+	for _, tagSize := range []int{0, 4, 8, 11, 17} {
+		if _, err := NewGCMWithTagSize(b, tagSize); !errors.Is(err, ErrTagSize) {
+			t.Errorf("[TestGCMMode] tag size %d failed: err != expected : '%v' != '%v'", tagSize, err, ErrTagSize)
+		}
+	}
+}
+
+func TestGCMCounterWrap(t *testing.T) {
+	// inc32 wraps the rightmost 32 bit without carrying into the rest of the counter block
+	b, err := NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("[TestGCMCounterWrap] failed: %v", err)
+	}
+	g, err := NewGCM(b)
+	if err != nil {
+		t.Fatalf("[TestGCMCounterWrap] failed: %v", err)
+	}
+
+	cb := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0xff, 0xff, 0xff, 0xfe}
+	expected := []byte{}
+	for _, last := range [][]byte{{0xff, 0xff, 0xff, 0xff}, {0x00, 0x00, 0x00, 0x00}, {0x00, 0x00, 0x00, 0x01}} {
+		state := append(append([]byte{}, cb[:12]...), last...)
+		b.blockCipher(state)
+		expected = append(expected, state...)
+	}
+
+	out := g.gctr(cb, 1, make([]byte, len(expected)))
+	if !bytes.Equal(out, expected) {
+		t.Errorf("[TestGCMCounterWrap] failed: out != expected :\nout:\t\t%s\nexpected:\t%s", PrintableBytes(out), PrintableBytes(expected))
+	}
+}
+
+func TestCCM(t *testing.T) {
+	cases := []struct {
+		key        []byte
+		nonce      []byte
+		additional []byte
+		plain      []byte
+		tagSize    int
+		expected   []byte
+	}{
+		// RFC 3610 Packet Vector #1
+		{
+			[]byte{0xc0, 0xc1, 0xc2, 0xc3, 0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xcb, 0xcc, 0xcd, 0xce, 0xcf},
+			[]byte{0x00, 0x00, 0x00, 0x03, 0x02, 0x01, 0x00, 0xa0, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{
+				0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+				0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e,
+			},
+			8,
+			[]byte{
+				0x58, 0x8c, 0x97, 0x9a, 0x61, 0xc6, 0x63, 0xd2, 0xf0, 0x66, 0xd0, 0xc2, 0xc0, 0xf9, 0x89, 0x80,
+				0x6d, 0x5f, 0x6b, 0x61, 0xda, 0xc3, 0x84, 0x17, 0xe8, 0xd1, 0x2c, 0xfd, 0xf9, 0x26, 0xe0,
+			},
+		},
+		// RFC 3610 Packet Vector #2
+		{
+			[]byte{0xc0, 0xc1, 0xc2, 0xc3, 0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xcb, 0xcc, 0xcd, 0xce, 0xcf},
+			[]byte{0x00, 0x00, 0x00, 0x04, 0x03, 0x02, 0x01, 0xa0, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{
+				0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+				0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+			},
+			8,
+			[]byte{
+				0x72, 0xc9, 0x1a, 0x36, 0xe1, 0x35, 0xf8, 0xcf, 0x29, 0x1c, 0xa8, 0x94, 0x08, 0x5c, 0x87, 0xe3,
+				0xcc, 0x15, 0xc4, 0x39, 0xc9, 0xe4, 0x3a, 0x3b, 0xa0, 0x91, 0xd5, 0x6e, 0x10, 0x40, 0x09, 0x16,
+			},
+		},
+		// RFC 3610 Packet Vector #3
+		{
+			[]byte{0xc0, 0xc1, 0xc2, 0xc3, 0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xcb, 0xcc, 0xcd, 0xce, 0xcf},
+			[]byte{0x00, 0x00, 0x00, 0x05, 0x04, 0x03, 0x02, 0xa0, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{
+				0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+				0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f, 0x20,
+			},
+			8,
+			[]byte{
+				0x51, 0xb1, 0xe5, 0xf4, 0x4a, 0x19, 0x7d, 0x1d, 0xa4, 0x6b, 0x0f, 0x8e, 0x2d, 0x28, 0x2a, 0xe8,
+				0x71, 0xe8, 0x38, 0xbb, 0x64, 0xda, 0x85, 0x96, 0x57, 0x4a, 0xda, 0xa7, 0x6f, 0xbd, 0x9f, 0xb0,
+				0xc5,
+			},
+		},
+		// NIST SP 800-38C Example 1
+		{
+			[]byte{0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f},
+			[]byte{0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{0x20, 0x21, 0x22, 0x23},
+			4,
+			[]byte{0x71, 0x62, 0x01, 0x5b, 0x4d, 0xac, 0x25, 0x5d},
+		},
+		// NIST SP 800-38C Example 2
+		{
+			[]byte{0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f},
+			[]byte{0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2a, 0x2b, 0x2c, 0x2d, 0x2e, 0x2f},
+			6,
+			[]byte{
+				0xd2, 0xa1, 0xf0, 0xe0, 0x51, 0xea, 0x5f, 0x62, 0x08, 0x1a, 0x77, 0x92, 0x07, 0x3d, 0x59, 0x3d,
+				0x1f, 0xc6, 0x4f, 0xbf, 0xac, 0xcd,
+			},
+		},
+		// NIST SP 800-38C Example 3
+		{
+			[]byte{0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f},
+			[]byte{0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13,
+			},
+			[]byte{
+				0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28, 0x29, 0x2a, 0x2b, 0x2c, 0x2d, 0x2e, 0x2f,
+				0x30, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x37,
+			},
+			8,
+			[]byte{
+				0xe3, 0xb2, 0x01, 0xa9, 0xf5, 0xb7, 0x1a, 0x7a, 0x9b, 0x1c, 0xea, 0xec, 0xcd, 0x97, 0xe7, 0x0b,
+				0x61, 0x76, 0xaa, 0xd9, 0xa4, 0x42, 0x8a, 0xa5, 0x48, 0x43, 0x92, 0xfb, 0xc1, 0xb0, 0x99, 0x51,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.key)
+		if err != nil {
+			t.Fatalf("[TestCCM] case %d failed: %v", i, err)
+		}
+		ccm, err := NewCCM(b, len(c.nonce), c.tagSize)
+		if err != nil {
+			t.Fatalf("[TestCCM] case %d failed: %v", i, err)
+		}
+
+		sealed := ccm.Seal(nil, c.nonce, c.plain, c.additional)
+		if !bytes.Equal(sealed, c.expected) {
+			t.Errorf("[TestCCM] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(c.expected))
+		}
+
+		opened, err := ccm.Open(nil, c.nonce, c.expected, c.additional)
+		if err != nil {
+			t.Errorf("[TestCCM] case %d failed: %v", i, err)
+		} else if !bytes.Equal(opened, c.plain) {
+			t.Errorf("[TestCCM] case %d failed: opened != expected :\nopened:\t\t%s\nexpected:\t%s", i, PrintableBytes(opened), PrintableBytes(c.plain))
+		}
+
+		// tampered cipher text must be rejected without releasing plain text
+		tampered := append([]byte{}, c.expected...)
+		tampered[0] ^= 0x01
+		opened, err = ccm.Open(nil, c.nonce, tampered, c.additional)
+		if !errors.Is(err, ErrAuthentication) || opened != nil {
+			t.Errorf("[TestCCM] case %d failed: err != expected : '%v' != '%v'", i, err, ErrAuthentication)
+		}
+	}
+}
+
+func TestCCMParameters(t *testing.T) {
+	key := []byte{0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f}
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestCCMParameters] failed: %v", err)
+	}
+
+	for _, nonceSize := range []int{0, 6, 14, 16} {
+		if _, err := NewCCM(b, nonceSize, 16); !errors.Is(err, ErrNonceSize) {
+			t.Errorf("[TestCCMParameters] nonce size %d failed: err != expected : '%v' != '%v'", nonceSize, err, ErrNonceSize)
+		}
+	}
+	for _, tagSize := range []int{0, 2, 5, 15, 18} {
+		if _, err := NewCCM(b, 13, tagSize); !errors.Is(err, ErrTagSize) {
+			t.Errorf("[TestCCMParameters] tag size %d failed: err != expected : '%v' != '%v'", tagSize, err, ErrTagSize)
+		}
+	}
+
+	// round trip for every nonce size with Cipher and InvCipher
+	plainText := []byte("CCM mode via Cipher and InvCipher")
+	for nonceSize := ccmMinimumNonceSize; nonceSize <= ccmMaximumNonceSize; nonceSize++ {
+		nonce := make([]byte, nonceSize)
+		cipherText, err := Cipher(plainText, key, ModeCCM, nonce, nil)
+		if err != nil {
+			t.Fatalf("[TestCCMParameters] nonce size %d failed: %v", nonceSize, err)
+		}
+		decrypted, err := InvCipher(cipherText, key, ModeCCM, nonce, nil)
+		if err != nil {
+			t.Fatalf("[TestCCMParameters] nonce size %d failed: %v", nonceSize, err)
+		}
+		if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestCCMParameters] nonce size %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", nonceSize, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+	}
+	if _, err := Cipher(plainText, key, ModeCCM, make([]byte, 6), nil); !errors.Is(err, ErrIVSize) {
+		t.Errorf("[TestCCMParameters] failed: err != expected : '%v' != '%v'", err, ErrIVSize)
+	}
+}
+
+func TestXTS(t *testing.T) {
+	// test vectors are defined in IEEE 1619-2007 Annex B
+	cases := []struct {
+		key      []byte
+		sector   uint64
+		plain    []byte
+		expected []byte
+	}{
+		// Vector 1
+		{
+			[]byte{
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			0,
+			[]byte{
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{
+				0x91, 0x7c, 0xf6, 0x9e, 0xbd, 0x68, 0xb2, 0xec, 0x9b, 0x9f, 0xe9, 0xa3, 0xea, 0xdd, 0xa6, 0x92,
+				0xcd, 0x43, 0xd2, 0xf5, 0x95, 0x98, 0xed, 0x85, 0x8c, 0x02, 0xc2, 0x65, 0x2f, 0xbf, 0x92, 0x2e,
+			},
+		},
+		// Vector 2
+		{
+			[]byte{
+				0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11,
+				0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22,
+			},
+			0x3333333333,
+			[]byte{
+				0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44,
+				0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44,
+			},
+			[]byte{
+				0xc4, 0x54, 0x18, 0x5e, 0x6a, 0x16, 0x93, 0x6e, 0x39, 0x33, 0x40, 0x38, 0xac, 0xef, 0x83, 0x8b,
+				0xfb, 0x18, 0x6f, 0xff, 0x74, 0x80, 0xad, 0xc4, 0x28, 0x93, 0x82, 0xec, 0xd6, 0xd3, 0x94, 0xf0,
+			},
+		},
+		// Vector 3
+		{
+			[]byte{
+				0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa, 0xf9, 0xf8, 0xf7, 0xf6, 0xf5, 0xf4, 0xf3, 0xf2, 0xf1, 0xf0,
+				0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22, 0x22,
+			},
+			0x3333333333,
+			[]byte{
+				0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44,
+				0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44, 0x44,
+			},
+			[]byte{
+				0xaf, 0x85, 0x33, 0x6b, 0x59, 0x7a, 0xfc, 0x1a, 0x90, 0x0b, 0x2e, 0xb2, 0x1e, 0xc9, 0x49, 0xd2,
+				0x92, 0xdf, 0x4c, 0x04, 0x7e, 0x0b, 0x21, 0x53, 0x21, 0x86, 0xa5, 0x97, 0x1a, 0x22, 0x7a, 0x89,
+			},
+		},
+		// Vector 15
+		{
+			[]byte{
+				0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa, 0xf9, 0xf8, 0xf7, 0xf6, 0xf5, 0xf4, 0xf3, 0xf2, 0xf1, 0xf0,
+				0xbf, 0xbe, 0xbd, 0xbc, 0xbb, 0xba, 0xb9, 0xb8, 0xb7, 0xb6, 0xb5, 0xb4, 0xb3, 0xb2, 0xb1, 0xb0,
+			},
+			0x123456789a,
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10,
+			},
+			[]byte{
+				0x6c, 0x16, 0x25, 0xdb, 0x46, 0x71, 0x52, 0x2d, 0x3d, 0x75, 0x99, 0x60, 0x1d, 0xe7, 0xca, 0x09,
+				0xed,
+			},
+		},
+		// Vector 16
+		{
+			[]byte{
+				0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa, 0xf9, 0xf8, 0xf7, 0xf6, 0xf5, 0xf4, 0xf3, 0xf2, 0xf1, 0xf0,
+				0xbf, 0xbe, 0xbd, 0xbc, 0xbb, 0xba, 0xb9, 0xb8, 0xb7, 0xb6, 0xb5, 0xb4, 0xb3, 0xb2, 0xb1, 0xb0,
+			},
+			0x123456789a,
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11,
+			},
+			[]byte{
+				0xd0, 0x69, 0x44, 0x4b, 0x7a, 0x7e, 0x0c, 0xab, 0x09, 0xe2, 0x44, 0x47, 0xd2, 0x4d, 0xeb, 0x1f,
+				0xed, 0xbf,
+			},
+		},
+		// Vector 17
+		{
+			[]byte{
+				0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa, 0xf9, 0xf8, 0xf7, 0xf6, 0xf5, 0xf4, 0xf3, 0xf2, 0xf1, 0xf0,
+				0xbf, 0xbe, 0xbd, 0xbc, 0xbb, 0xba, 0xb9, 0xb8, 0xb7, 0xb6, 0xb5, 0xb4, 0xb3, 0xb2, 0xb1, 0xb0,
+			},
+			0x123456789a,
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12,
+			},
+			[]byte{
+				0xe5, 0xdf, 0x13, 0x51, 0xc0, 0x54, 0x4b, 0xa1, 0x35, 0x0b, 0x33, 0x63, 0xcd, 0x8e, 0xf4, 0xbe,
+				0xed, 0xbf, 0x9d,
+			},
+		},
+		// Vector 18
+		{
+			[]byte{
+				0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa, 0xf9, 0xf8, 0xf7, 0xf6, 0xf5, 0xf4, 0xf3, 0xf2, 0xf1, 0xf0,
+				0xbf, 0xbe, 0xbd, 0xbc, 0xbb, 0xba, 0xb9, 0xb8, 0xb7, 0xb6, 0xb5, 0xb4, 0xb3, 0xb2, 0xb1, 0xb0,
+			},
+			0x123456789a,
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13,
+			},
+			[]byte{
+				0x9d, 0x84, 0xc8, 0x13, 0xf7, 0x19, 0xaa, 0x2c, 0x7b, 0xe3, 0xf6, 0x61, 0x71, 0xc7, 0xc5, 0xc2,
+				0xed, 0xbf, 0x9d, 0xac,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		x, err := NewXTS(c.key)
+		if err != nil {
+			t.Fatalf("[TestXTS] case %d failed: %v", i, err)
+		}
+
+		cipherText, err := x.Encrypt(c.plain, SectorTweak(c.sector))
+		if err != nil {
+			t.Fatalf("[TestXTS] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(cipherText, c.expected) {
+			t.Errorf("[TestXTS] case %d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", i, PrintableBytes(cipherText), PrintableBytes(c.expected))
+		}
+
+		plainText, err := x.Decrypt(c.expected, SectorTweak(c.sector))
+		if err != nil {
+			t.Fatalf("[TestXTS] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(plainText, c.plain) {
+			t.Errorf("[TestXTS] case %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", i, PrintableBytes(plainText), PrintableBytes(c.plain))
+		}
+	}
+}
+
+func TestXTSMode(t *testing.T) {
+	key := make([]byte, 64)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	tweak := SectorTweak(42)
+
+	// every tail length including block aligned data unit
+	for l := xtsBlockSize; l <= 3*xtsBlockSize; l++ {
+		plainText := make([]byte, l)
+		for i := range plainText {
+			plainText[i] = byte(i * 7)
+		}
+		cipherText, err := Cipher(plainText, key, ModeXTS, tweak, nil)
+		if err != nil {
+			t.Fatalf("[TestXTSMode] length %d failed: %v", l, err)
+		}
+		decrypted, err := InvCipher(cipherText, key, ModeXTS, tweak, nil)
+		if err != nil {
+			t.Fatalf("[TestXTSMode] length %d failed: %v", l, err)
+		}
+		if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestXTSMode] length %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", l, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+	}
+
+	if _, err := Cipher(make([]byte, 16), key[:48], ModeXTS, tweak, nil); !errors.Is(err, ErrKeySize) {
+		t.Errorf("[TestXTSMode] failed: err != expected : '%v' != '%v'", err, ErrKeySize)
+	}
+	if _, err := Cipher(make([]byte, 16), key, ModeXTS, tweak[:8], nil); !errors.Is(err, ErrIVSize) {
+		t.Errorf("[TestXTSMode] failed: err != expected : '%v' != '%v'", err, ErrIVSize)
+	}
+	if _, err := Cipher(make([]byte, 15), key, ModeXTS, tweak, nil); !errors.Is(err, ErrPlaintextLength) {
+		t.Errorf("[TestXTSMode] failed: err != expected : '%v' != '%v'", err, ErrPlaintextLength)
+	}
+	if _, err := InvCipher(make([]byte, 15), key, ModeXTS, tweak, nil); !errors.Is(err, ErrCiphertextLength) {
+		t.Errorf("[TestXTSMode] failed: err != expected : '%v' != '%v'", err, ErrCiphertextLength)
+	}
+}
+
+func TestSIV(t *testing.T) {
+	cases := []struct {
+		key        []byte
+		additional [][]byte
+		plain      []byte
+		expected   []byte
+	}{
+		// RFC 5297 A.1 Deterministic Authenticated Encryption
+		{
+			[]byte{
+				0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa, 0xf9, 0xf8, 0xf7, 0xf6, 0xf5, 0xf4, 0xf3, 0xf2, 0xf1, 0xf0,
+				0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff,
+			},
+			[][]byte{
+				[]byte{
+					0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+					0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27,
+				},
+			},
+			[]byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee},
+			[]byte{
+				0x85, 0x63, 0x2d, 0x07, 0xc6, 0xe8, 0xf3, 0x7f, 0x95, 0x0a, 0xcd, 0x32, 0x0a, 0x2e, 0xcc, 0x93,
+				0x40, 0xc0, 0x2b, 0x96, 0x90, 0xc4, 0xdc, 0x04, 0xda, 0xef, 0x7f, 0x6a, 0xfe, 0x5c,
+			},
+		},
+		// RFC 5297 A.2 Nonce-Based Authenticated Encryption
+		{
+			[]byte{
+				0x7f, 0x7e, 0x7d, 0x7c, 0x7b, 0x7a, 0x79, 0x78, 0x77, 0x76, 0x75, 0x74, 0x73, 0x72, 0x71, 0x70,
+				0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f,
+			},
+			[][]byte{
+				[]byte{
+					0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+					0xde, 0xad, 0xda, 0xda, 0xde, 0xad, 0xda, 0xda, 0xff, 0xee, 0xdd, 0xcc, 0xbb, 0xaa, 0x99, 0x88,
+					0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x00,
+				},
+				[]byte{0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0},
+				[]byte{0x09, 0xf9, 0x11, 0x02, 0x9d, 0x74, 0xe3, 0x5b, 0xd8, 0x41, 0x56, 0xc5, 0x63, 0x56, 0x88, 0xc0},
+			},
+			[]byte{
+				0x74, 0x68, 0x69, 0x73, 0x20, 0x69, 0x73, 0x20, 0x73, 0x6f, 0x6d, 0x65, 0x20, 0x70, 0x6c, 0x61,
+				0x69, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x20, 0x74, 0x6f, 0x20, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70,
+				0x74, 0x20, 0x75, 0x73, 0x69, 0x6e, 0x67, 0x20, 0x53, 0x49, 0x56, 0x2d, 0x41, 0x45, 0x53,
+			},
+			[]byte{
+				0x7b, 0xdb, 0x6e, 0x3b, 0x43, 0x26, 0x67, 0xeb, 0x06, 0xf4, 0xd1, 0x4b, 0xff, 0x2f, 0xbd, 0x0f,
+				0xcb, 0x90, 0x0f, 0x2f, 0xdd, 0xbe, 0x40, 0x43, 0x26, 0x60, 0x19, 0x65, 0xc8, 0x89, 0xbf, 0x17,
+				0xdb, 0xa7, 0x7c, 0xeb, 0x09, 0x4f, 0xa6, 0x63, 0xb7, 0xa3, 0xf7, 0x48, 0xba, 0x8a, 0xf8, 0x29,
+				0xea, 0x64, 0xad, 0x54, 0x4a, 0x27, 0x2e, 0x9c, 0x48, 0x5b, 0x62, 0xa3, 0xfd, 0x5c, 0x0d,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		s, err := NewSIV(c.key)
+		if err != nil {
+			t.Fatalf("[TestSIV] case %d failed: %v", i, err)
+		}
+
+		sealed := s.Seal(nil, c.plain, c.additional...)
+		if !bytes.Equal(sealed, c.expected) {
+			t.Errorf("[TestSIV] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(c.expected))
+		}
+
+		opened, err := s.Open(nil, c.expected, c.additional...)
+		if err != nil {
+			t.Errorf("[TestSIV] case %d failed: %v", i, err)
+		} else if !bytes.Equal(opened, c.plain) {
+			t.Errorf("[TestSIV] case %d failed: opened != expected :\nopened:\t\t%s\nexpected:\t%s", i, PrintableBytes(opened), PrintableBytes(c.plain))
+		}
+
+		// dropping an additional data component must be rejected
+		opened, err = s.Open(nil, c.expected, c.additional[1:]...)
+		if !errors.Is(err, ErrAuthentication) || opened != nil {
+			t.Errorf("[TestSIV] case %d failed: err != expected : '%v' != '%v'", i, err, ErrAuthentication)
+		}
+	}
+
+	if _, err := NewSIV(make([]byte, 16)); !errors.Is(err, ErrKeySize) {
+		t.Errorf("[TestSIV] failed: err != expected : '%v' != '%v'", err, ErrKeySize)
+	}
+}
+
+func TestPolyval(t *testing.T) {
+	// test vector is defined in RFC 8452 Appendix A
+	h := []byte{0x25, 0x62, 0x93, 0x47, 0x58, 0x92, 0x42, 0x76, 0x1d, 0x31, 0xf8, 0x26, 0xba, 0x4b, 0x75, 0x7b}
+	x := []byte{
+		0x4f, 0x4f, 0x95, 0x66, 0x8c, 0x83, 0xdf, 0xb6, 0x40, 0x17, 0x62, 0xbb, 0x2d, 0x01, 0xa2, 0x62,
+		0xd1, 0xa2, 0x4d, 0xdd, 0x27, 0x21, 0xd0, 0x06, 0xbb, 0xe4, 0x5f, 0x20, 0xd3, 0xc9, 0xf3, 0x62,
+	}
+	expected := []byte{0xf7, 0xa3, 0xb4, 0x7b, 0x84, 0x61, 0x19, 0xfa, 0xe5, 0xb7, 0x86, 0x6c, 0xf5, 0xe5, 0xb7, 0x7e}
+
+	s := polyvalBlocks(h, x)
+	if !bytes.Equal(s, expected) {
+		t.Errorf("[TestPolyval] failed: result != expected : %s != %s", PrintableBytes(s), PrintableBytes(expected))
+	}
+}
+
+func TestGCMSIV(t *testing.T) {
+	// test vectors are defined in RFC 8452 Appendix C
+	cases := []struct {
+		key        []byte
+		nonce      []byte
+		additional []byte
+		plain      []byte
+		expected   []byte
+	}{
+		// RFC 8452 C.1 (no plaintext)
+		{
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{},
+			[]byte{0xdc, 0x20, 0xe2, 0xd8, 0x3f, 0x25, 0x70, 0x5b, 0xb4, 0x9e, 0x43, 0x9e, 0xca, 0x56, 0xde, 0x25},
+		},
+		// RFC 8452 C.1 (8 byte plaintext)
+		{
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{
+				0xb5, 0xd8, 0x39, 0x33, 0x0a, 0xc7, 0xb7, 0x86, 0x57, 0x87, 0x82, 0xff, 0xf6, 0x01, 0x3b, 0x81,
+				0x5b, 0x28, 0x7c, 0x22, 0x49, 0x3a, 0x36, 0x4c,
+			},
+		},
+		// RFC 8452 C.1 (12 byte plaintext)
+		{
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{
+				0x73, 0x23, 0xea, 0x61, 0xd0, 0x59, 0x32, 0x26, 0x00, 0x47, 0xd9, 0x42, 0xa4, 0x97, 0x8d, 0xb3,
+				0x57, 0x39, 0x1a, 0x0b, 0xc4, 0xfd, 0xec, 0x8b, 0x0d, 0x10, 0x66, 0x39,
+			},
+		},
+		// RFC 8452 C.1 (16 byte plaintext)
+		{
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{
+				0x74, 0x3f, 0x7c, 0x80, 0x77, 0xab, 0x25, 0xf8, 0x62, 0x4e, 0x2e, 0x94, 0x85, 0x79, 0xcf, 0x77,
+				0x30, 0x3a, 0xaf, 0x90, 0xf6, 0xfe, 0x21, 0x19, 0x9c, 0x60, 0x68, 0x57, 0x74, 0x37, 0xa0, 0xc4,
+			},
+		},
+		// RFC 8452 C.1 (with additional data)
+		{
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0x01},
+			[]byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{
+				0x1e, 0x6d, 0xab, 0xa3, 0x56, 0x69, 0xf4, 0x27, 0x3b, 0x0a, 0x1a, 0x25, 0x60, 0x96, 0x9c, 0xdf,
+				0x79, 0x0d, 0x99, 0x75, 0x9a, 0xbd, 0x15, 0x08,
+			},
+		},
+		// RFC 8452 C.2 (no plaintext)
+		{
+			[]byte{
+				0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{},
+			[]byte{0x07, 0xf5, 0xf4, 0x16, 0x9b, 0xbf, 0x55, 0xa8, 0x40, 0x0c, 0xd4, 0x7e, 0xa6, 0xfd, 0x40, 0x0f},
+		},
+		// RFC 8452 C.2 (8 byte plaintext)
+		{
+			[]byte{
+				0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{},
+			[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{
+				0xc2, 0xef, 0x32, 0x8e, 0x5c, 0x71, 0xc8, 0x3b, 0x84, 0x31, 0x22, 0x13, 0x0f, 0x73, 0x64, 0xb7,
+				0x61, 0xe0, 0xb9, 0x74, 0x27, 0xe3, 0xdf, 0x28,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		g, err := NewGCMSIV(c.key)
+		if err != nil {
+			t.Fatalf("[TestGCMSIV] case %d failed: %v", i, err)
+		}
+
+		sealed := g.Seal(nil, c.nonce, c.plain, c.additional)
+		if !bytes.Equal(sealed, c.expected) {
+			t.Errorf("[TestGCMSIV] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(c.expected))
+		}
+
+		opened, err := g.Open(nil, c.nonce, c.expected, c.additional)
+		if err != nil {
+			t.Errorf("[TestGCMSIV] case %d failed: %v", i, err)
+		} else if !bytes.Equal(opened, c.plain) {
+			t.Errorf("[TestGCMSIV] case %d failed: opened != expected :\nopened:\t\t%s\nexpected:\t%s", i, PrintableBytes(opened), PrintableBytes(c.plain))
+		}
+
+		tampered := append([]byte{}, c.expected...)
+		tampered[len(tampered)-1] ^= 0x01
+		opened, err = g.Open(nil, c.nonce, tampered, c.additional)
+		if !errors.Is(err, ErrAuthentication) || opened != nil {
+			t.Errorf("[TestGCMSIV] case %d failed: err != expected : '%v' != '%v'", i, err, ErrAuthentication)
+		}
+	}
+
+	if _, err := NewGCMSIV(make([]byte, 24)); !errors.Is(err, ErrKeySize) {
+		t.Errorf("[TestGCMSIV] failed: err != expected : '%v' != '%v'", err, ErrKeySize)
+	}
+}
+
+func TestOCB(t *testing.T) {
+	cases := []struct {
+		key        []byte
+		tagSize    int
+		nonce      []byte
+		additional []byte
+		plain      []byte
+		expected   []byte
+	}{
+		// RFC 7253 Appendix A
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x00},
+			[]byte{},
+			[]byte{},
+			[]byte{0x78, 0x54, 0x07, 0xbf, 0xff, 0xc8, 0xad, 0x9e, 0xdc, 0xc5, 0x52, 0x0a, 0xc9, 0x11, 0x1e, 0xe6},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x01},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{
+				0x68, 0x20, 0xb3, 0x65, 0x7b, 0x6f, 0x61, 0x5a, 0x57, 0x25, 0xbd, 0xa0, 0xd3, 0xb4, 0xeb, 0x3a,
+				0x25, 0x7c, 0x9a, 0xf1, 0xf8, 0xf0, 0x30, 0x09,
+			},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x02},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{},
+			[]byte{0x81, 0x01, 0x7f, 0x82, 0x03, 0xf0, 0x81, 0x27, 0x71, 0x52, 0xfa, 0xde, 0x69, 0x4a, 0x0a, 0x00},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x03},
+			[]byte{},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			[]byte{
+				0x45, 0xdd, 0x69, 0xf8, 0xf5, 0xaa, 0xe7, 0x24, 0x14, 0x05, 0x4c, 0xd1, 0xf3, 0x5d, 0x82, 0x76,
+				0x0b, 0x2c, 0xd0, 0x0d, 0x2f, 0x99, 0xbf, 0xa9,
+			},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x04},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{
+				0x57, 0x1d, 0x53, 0x5b, 0x60, 0xb2, 0x77, 0x18, 0x8b, 0xe5, 0x14, 0x71, 0x70, 0xa9, 0xa2, 0x2c,
+				0x3a, 0xd7, 0xa4, 0xff, 0x38, 0x35, 0xb8, 0xc5, 0x70, 0x1c, 0x1c, 0xce, 0xc8, 0xfc, 0x33, 0x58,
+			},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x05},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{},
+			[]byte{0x8c, 0xf7, 0x61, 0xb6, 0x90, 0x2e, 0xf7, 0x64, 0x46, 0x2a, 0xd8, 0x64, 0x98, 0xca, 0x6b, 0x97},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x06},
+			[]byte{},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{
+				0x5c, 0xe8, 0x8e, 0xc2, 0xe0, 0x69, 0x27, 0x06, 0xa9, 0x15, 0xc0, 0x0a, 0xeb, 0x8b, 0x23, 0x96,
+				0xf4, 0x0e, 0x1c, 0x74, 0x3f, 0x52, 0x43, 0x6b, 0xdf, 0x06, 0xd8, 0xfa, 0x1e, 0xca, 0x34, 0x3d,
+			},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x07},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			},
+			[]byte{
+				0x1c, 0xa2, 0x20, 0x73, 0x08, 0xc8, 0x7c, 0x01, 0x07, 0x56, 0x10, 0x4d, 0x88, 0x40, 0xce, 0x19,
+				0x52, 0xf0, 0x96, 0x73, 0xa4, 0x48, 0xa1, 0x22, 0xc9, 0x2c, 0x62, 0x24, 0x10, 0x51, 0xf5, 0x73,
+				0x56, 0xd7, 0xf3, 0xc9, 0x0b, 0xb0, 0xe0, 0x7f,
+			},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x08},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			},
+			[]byte{},
+			[]byte{0x6d, 0xc2, 0x25, 0xa0, 0x71, 0xfc, 0x1b, 0x9f, 0x7c, 0x69, 0xf9, 0x3b, 0x0f, 0x1e, 0x10, 0xde},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			16,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x09},
+			[]byte{},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			},
+			[]byte{
+				0x22, 0x1b, 0xd0, 0xde, 0x7f, 0xa6, 0xfe, 0x99, 0x3e, 0xcc, 0xd7, 0x69, 0x46, 0x0a, 0x0a, 0xf2,
+				0xd6, 0xcd, 0xed, 0x0c, 0x39, 0x5b, 0x1c, 0x3c, 0xe7, 0x25, 0xf3, 0x24, 0x94, 0xb9, 0xf9, 0x14,
+				0xd8, 0x5c, 0x0b, 0x1e, 0xb3, 0x83, 0x57, 0xff,
+			},
+		},
+		// RFC 7253 Appendix A (TAGLEN = 96)
+		{
+			[]byte{0x0f, 0x0e, 0x0d, 0x0c, 0x0b, 0x0a, 0x09, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, 0x00},
+			12,
+			[]byte{0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x0d},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+				0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27,
+			},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+				0x20, 0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27,
+			},
+			[]byte{
+				0x17, 0x92, 0xa4, 0xe3, 0x1e, 0x07, 0x55, 0xfb, 0x03, 0xe3, 0x1b, 0x22, 0x11, 0x6e, 0x6c, 0x2d,
+				0xdf, 0x9e, 0xfd, 0x6e, 0x33, 0xd5, 0x36, 0xf1, 0xa0, 0x12, 0x4b, 0x0a, 0x55, 0xba, 0xe8, 0x84,
+				0xed, 0x93, 0x48, 0x15, 0x29, 0xc7, 0x6b, 0x6a, 0xd0, 0xc5, 0x15, 0xf4, 0xd1, 0xcd, 0xd4, 0xfd,
+				0xac, 0x4f, 0x02, 0xaa,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.key)
+		if err != nil {
+			t.Fatalf("[TestOCB] case %d failed: %v", i, err)
+		}
+		o, err := NewOCB(b, c.tagSize)
+		if err != nil {
+			t.Fatalf("[TestOCB] case %d failed: %v", i, err)
+		}
+
+		sealed := o.Seal(nil, c.nonce, c.plain, c.additional)
+		if !bytes.Equal(sealed, c.expected) {
+			t.Errorf("[TestOCB] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(c.expected))
+		}
+
+		opened, err := o.Open(nil, c.nonce, c.expected, c.additional)
+		if err != nil {
+			t.Errorf("[TestOCB] case %d failed: %v", i, err)
+		} else if !bytes.Equal(opened, c.plain) {
+			t.Errorf("[TestOCB] case %d failed: opened != expected :\nopened:\t\t%s\nexpected:\t%s", i, PrintableBytes(opened), PrintableBytes(c.plain))
+		}
+
+		tampered := append([]byte{}, c.expected...)
+		tampered[0] ^= 0x01
+		opened, err = o.Open(nil, c.nonce, tampered, c.additional)
+		if !errors.Is(err, ErrAuthentication) || opened != nil {
+			t.Errorf("[TestOCB] case %d failed: err != expected : '%v' != '%v'", i, err, ErrAuthentication)
 		}
 	}
+
+	b, err := NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("[TestOCB] failed: %v", err)
+	}
+	for _, tagSize := range []int{0, 17} {
+		if _, err := NewOCB(b, tagSize); !errors.Is(err, ErrTagSize) {
+			t.Errorf("[TestOCB] tag size %d failed: err != expected : '%v' != '%v'", tagSize, err, ErrTagSize)
+		}
+	}
+}
+
+func TestEAX(t *testing.T) {
+	cases := []struct {
+		key        []byte
+		nonce      []byte
+		additional []byte
+		plain      []byte
+		expected   []byte
+	}{
+		// Test vectors from "The EAX Mode of Operation"
+		{
+			[]byte{0x23, 0x39, 0x52, 0xde, 0xe4, 0xd5, 0xed, 0x5f, 0x9b, 0x9c, 0x6d, 0x6f, 0xf8, 0x0f, 0xf4, 0x78},
+			[]byte{0x62, 0xec, 0x67, 0xf9, 0xc3, 0xa4, 0xa4, 0x07, 0xfc, 0xb2, 0xa8, 0xc4, 0x90, 0x31, 0xa8, 0xb3},
+			[]byte{0x6b, 0xfb, 0x91, 0x4f, 0xd0, 0x7e, 0xae, 0x6b},
+			[]byte{},
+			[]byte{0xe0, 0x37, 0x83, 0x0e, 0x83, 0x89, 0xf2, 0x7b, 0x02, 0x5a, 0x2d, 0x65, 0x27, 0xe7, 0x9d, 0x01},
+		},
+		{
+			[]byte{0x91, 0x94, 0x5d, 0x3f, 0x4d, 0xcb, 0xee, 0x0b, 0xf4, 0x5e, 0xf5, 0x22, 0x55, 0xf0, 0x95, 0xa4},
+			[]byte{0xbe, 0xca, 0xf0, 0x43, 0xb0, 0xa2, 0x3d, 0x84, 0x31, 0x94, 0xba, 0x97, 0x2c, 0x66, 0xde, 0xbd},
+			[]byte{0xfa, 0x3b, 0xfd, 0x48, 0x06, 0xeb, 0x53, 0xfa},
+			[]byte{0xf7, 0xfb},
+			[]byte{
+				0x19, 0xdd, 0x5c, 0x4c, 0x93, 0x31, 0x04, 0x9d, 0x0b, 0xda, 0xb0, 0x27, 0x74, 0x08, 0xf6, 0x79,
+				0x67, 0xe5,
+			},
+		},
+		{
+			[]byte{0x01, 0xf7, 0x4a, 0xd6, 0x40, 0x77, 0xf2, 0xe7, 0x04, 0xc0, 0xf6, 0x0a, 0xda, 0x3d, 0xd5, 0x23},
+			[]byte{0x70, 0xc3, 0xdb, 0x4f, 0x0d, 0x26, 0x36, 0x84, 0x00, 0xa1, 0x0e, 0xd0, 0x5d, 0x2b, 0xff, 0x5e},
+			[]byte{0x23, 0x4a, 0x34, 0x63, 0xc1, 0x26, 0x4a, 0xc6},
+			[]byte{0x1a, 0x47, 0xcb, 0x49, 0x33},
+			[]byte{
+				0xd8, 0x51, 0xd5, 0xba, 0xe0, 0x3a, 0x59, 0xf2, 0x38, 0xa2, 0x3e, 0x39, 0x19, 0x9d, 0xc9, 0x26,
+				0x66, 0x26, 0xc4, 0x0f, 0x80,
+			},
+		},
+		{
+			[]byte{0xd0, 0x7c, 0xf6, 0xcb, 0xb7, 0xf3, 0x13, 0xbd, 0xde, 0x66, 0xb7, 0x27, 0xaf, 0xd3, 0xc5, 0xe8},
+			[]byte{0x84, 0x08, 0xdf, 0xff, 0x3c, 0x1a, 0x2b, 0x12, 0x92, 0xdc, 0x19, 0x9e, 0x46, 0xb7, 0xd6, 0x17},
+			[]byte{0x33, 0xcc, 0xe2, 0xea, 0xbf, 0xf5, 0xa7, 0x9d},
+			[]byte{0x48, 0x1c, 0x9e, 0x39, 0xb1},
+			[]byte{
+				0x63, 0x2a, 0x9d, 0x13, 0x1a, 0xd4, 0xc1, 0x68, 0xa4, 0x22, 0x5d, 0x8e, 0x1f, 0xf7, 0x55, 0x93,
+				0x99, 0x74, 0xa7, 0xbe, 0xde,
+			},
+		},
+		{
+			[]byte{0x35, 0xb6, 0xd0, 0x58, 0x00, 0x05, 0xbb, 0xc1, 0x2b, 0x05, 0x87, 0x12, 0x45, 0x57, 0xd2, 0xc2},
+			[]byte{0xfd, 0xb6, 0xb0, 0x66, 0x76, 0xee, 0xdc, 0x5c, 0x61, 0xd7, 0x42, 0x76, 0xe1, 0xf8, 0xe8, 0x16},
+			[]byte{0xae, 0xb9, 0x6e, 0xae, 0xbe, 0x29, 0x70, 0xe9},
+			[]byte{0x40, 0xd0, 0xc0, 0x7d, 0xa5, 0xe4},
+			[]byte{
+				0x07, 0x1d, 0xfe, 0x16, 0xc6, 0x75, 0xcb, 0x06, 0x77, 0xe5, 0x36, 0xf7, 0x3a, 0xfe, 0x6a, 0x14,
+				0xb7, 0x4e, 0xe4, 0x98, 0x44, 0xdd,
+			},
+		},
+		{
+			[]byte{0xbd, 0x8e, 0x6e, 0x11, 0x47, 0x5e, 0x60, 0xb2, 0x68, 0x78, 0x4c, 0x38, 0xc6, 0x2f, 0xeb, 0x22},
+			[]byte{0x6e, 0xac, 0x5c, 0x93, 0x07, 0x2d, 0x8e, 0x85, 0x13, 0xf7, 0x50, 0x93, 0x5e, 0x46, 0xda, 0x1b},
+			[]byte{0xd4, 0x48, 0x2d, 0x1c, 0xa7, 0x8d, 0xce, 0x0f},
+			[]byte{0x4d, 0xe3, 0xb3, 0x5c, 0x3f, 0xc0, 0x39, 0x24, 0x5b, 0xd1, 0xfb, 0x7d},
+			[]byte{
+				0x83, 0x5b, 0xb4, 0xf1, 0x5d, 0x74, 0x3e, 0x35, 0x0e, 0x72, 0x84, 0x14, 0xab, 0xb8, 0x64, 0x4f,
+				0xd6, 0xcc, 0xb8, 0x69, 0x47, 0xc5, 0xe1, 0x05, 0x90, 0x21, 0x0a, 0x4f,
+			},
+		},
+		{
+			[]byte{0x7c, 0x77, 0xd6, 0xe8, 0x13, 0xbe, 0xd5, 0xac, 0x98, 0xba, 0xa4, 0x17, 0x47, 0x7a, 0x2e, 0x7d},
+			[]byte{0x1a, 0x8c, 0x98, 0xdc, 0xd7, 0x3d, 0x38, 0x39, 0x3b, 0x2b, 0xf1, 0x56, 0x9d, 0xee, 0xfc, 0x19},
+			[]byte{0x65, 0xd2, 0x01, 0x79, 0x90, 0xd6, 0x25, 0x28},
+			[]byte{
+				0x8b, 0x0a, 0x79, 0x30, 0x6c, 0x9c, 0xe7, 0xed, 0x99, 0xda, 0xe4, 0xf8, 0x7f, 0x8d, 0xd6, 0x16,
+				0x36,
+			},
+			[]byte{
+				0x02, 0x08, 0x3e, 0x39, 0x79, 0xda, 0x01, 0x48, 0x12, 0xf5, 0x9f, 0x11, 0xd5, 0x26, 0x30, 0xda,
+				0x30, 0x13, 0x73, 0x27, 0xd1, 0x06, 0x49, 0xb0, 0xaa, 0x6e, 0x1c, 0x18, 0x1d, 0xb6, 0x17, 0xd7,
+				0xf2,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.key)
+		if err != nil {
+			t.Fatalf("[TestEAX] case %d failed: %v", i, err)
+		}
+		e, err := NewEAX(b, 16)
+		if err != nil {
+			t.Fatalf("[TestEAX] case %d failed: %v", i, err)
+		}
+
+		sealed := e.Seal(nil, c.nonce, c.plain, c.additional)
+		if !bytes.Equal(sealed, c.expected) {
+			t.Errorf("[TestEAX] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(c.expected))
+		}
+
+		opened, err := e.Open(nil, c.nonce, c.expected, c.additional)
+		if err != nil {
+			t.Errorf("[TestEAX] case %d failed: %v", i, err)
+		} else if !bytes.Equal(opened, c.plain) {
+			t.Errorf("[TestEAX] case %d failed: opened != expected :\nopened:\t\t%s\nexpected:\t%s", i, PrintableBytes(opened), PrintableBytes(c.plain))
+		}
+
+		// truncated tag is a prefix of the full tag
+		e8, err := NewEAX(b, 8)
+		if err != nil {
+			t.Fatalf("[TestEAX] case %d failed: %v", i, err)
+		}
+		truncated := c.expected[:len(c.expected)-8]
+		if sealed := e8.Seal(nil, c.nonce, c.plain, c.additional); !bytes.Equal(sealed, truncated) {
+			t.Errorf("[TestEAX] case %d failed: sealed != expected :\nsealed:\t\t%s\nexpected:\t%s", i, PrintableBytes(sealed), PrintableBytes(truncated))
+		}
+
+		tampered := append([]byte{}, c.expected...)
+		tampered[len(tampered)-1] ^= 0x01
+		opened, err = e.Open(nil, c.nonce, tampered, c.additional)
+		if !errors.Is(err, ErrAuthentication) || opened != nil {
+			t.Errorf("[TestEAX] case %d failed: err != expected : '%v' != '%v'", i, err, ErrAuthentication)
+		}
+	}
+
+	b, err := NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("[TestEAX] failed: %v", err)
+	}
+	for _, tagSize := range []int{0, 17} {
+		if _, err := NewEAX(b, tagSize); !errors.Is(err, ErrTagSize) {
+			t.Errorf("[TestEAX] tag size %d failed: err != expected : '%v' != '%v'", tagSize, err, ErrTagSize)
+		}
+	}
+}
+
+func TestKeyWrap(t *testing.T) {
+	cases := []struct {
+		kek      []byte
+		key      []byte
+		expected []byte
+	}{
+		// RFC 4.1 Wrap 128 bits of Key Data with a 128-bit KEK
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{
+				0x1f, 0xa6, 0x8b, 0x0a, 0x81, 0x12, 0xb4, 0x47, 0xae, 0xf3, 0x4b, 0xd8, 0xfb, 0x5a, 0x7b, 0x82,
+				0x9d, 0x3e, 0x86, 0x23, 0x71, 0xd2, 0xcf, 0xe5,
+			},
+		},
+		// RFC 4.2 Wrap 128 bits of Key Data with a 192-bit KEK
+		{
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{
+				0x96, 0x77, 0x8b, 0x25, 0xae, 0x6c, 0xa4, 0x35, 0xf9, 0x2b, 0x5b, 0x97, 0xc0, 0x50, 0xae, 0xd2,
+				0x46, 0x8a, 0xb8, 0xa1, 0x7a, 0xd8, 0x4e, 0x5d,
+			},
+		},
+		// RFC 4.3 Wrap 128 bits of Key Data with a 256-bit KEK
+		{
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+			},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{
+				0x64, 0xe8, 0xc3, 0xf9, 0xce, 0x0f, 0x5b, 0xa2, 0x63, 0xe9, 0x77, 0x79, 0x05, 0x81, 0x8a, 0x2a,
+				0x93, 0xc8, 0x19, 0x1e, 0x7d, 0x6e, 0x8a, 0xe7,
+			},
+		},
+		// RFC 4.4 Wrap 192 bits of Key Data with a 192-bit KEK
+		{
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			},
+			[]byte{
+				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+			},
+			[]byte{
+				0x03, 0x1d, 0x33, 0x26, 0x4e, 0x15, 0xd3, 0x32, 0x68, 0xf2, 0x4e, 0xc2, 0x60, 0x74, 0x3e, 0xdc,
+				0xe1, 0xc6, 0xc7, 0xdd, 0xee, 0x72, 0x5a, 0x93, 0x6b, 0xa8, 0x14, 0x91, 0x5c, 0x67, 0x62, 0xd2,
+			},
+		},
+		// RFC 4.5 Wrap 192 bits of Key Data with a 256-bit KEK
+		{
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+			},
+			[]byte{
+				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+			},
+			[]byte{
+				0xa8, 0xf9, 0xbc, 0x16, 0x12, 0xc6, 0x8b, 0x3f, 0xf6, 0xe6, 0xf4, 0xfb, 0xe3, 0x0e, 0x71, 0xe4,
+				0x76, 0x9c, 0x8b, 0x80, 0xa3, 0x2c, 0xb8, 0x95, 0x8c, 0xd5, 0xd1, 0x7d, 0x6b, 0x25, 0x4d, 0xa1,
+			},
+		},
+		// RFC 4.6 Wrap 256 bits of Key Data with a 256-bit KEK
+		{
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+			},
+			[]byte{
+				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+			},
+			[]byte{
+				0x28, 0xc9, 0xf4, 0x04, 0xc4, 0xb8, 0x10, 0xf4, 0xcb, 0xcc, 0xb3, 0x5c, 0xfb, 0x87, 0xf8, 0x26,
+				0x3f, 0x57, 0x86, 0xe2, 0xd8, 0x0e, 0xd3, 0x26, 0xcb, 0xc7, 0xf0, 0xe7, 0x1a, 0x99, 0xf4, 0x3b,
+				0xfb, 0x98, 0x8b, 0x9b, 0x7a, 0x02, 0xdd, 0x21,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.kek)
+		if err != nil {
+			t.Fatalf("[TestKeyWrap] case %d failed: %v", i, err)
+		}
+
+		wrapped, err := Wrap(b, c.key)
+		if err != nil {
+			t.Errorf("[TestKeyWrap] case %d failed: %v", i, err)
+		} else if !bytes.Equal(wrapped, c.expected) {
+			t.Errorf("[TestKeyWrap] case %d failed: wrapped != expected :\nwrapped:\t%s\nexpected:\t%s", i, PrintableBytes(wrapped), PrintableBytes(c.expected))
+		}
+
+		unwrapped, err := Unwrap(b, c.expected)
+		if err != nil {
+			t.Errorf("[TestKeyWrap] case %d failed: %v", i, err)
+		} else if !bytes.Equal(unwrapped, c.key) {
+			t.Errorf("[TestKeyWrap] case %d failed: unwrapped != expected :\nunwrapped:\t%s\nexpected:\t%s", i, PrintableBytes(unwrapped), PrintableBytes(c.key))
+		}
+
+		tampered := append([]byte{}, c.expected...)
+		tampered[len(tampered)-1] ^= 0x01
+		if _, err := Unwrap(b, tampered); !errors.Is(err, ErrIntegrity) {
+			t.Errorf("[TestKeyWrap] case %d failed: err != expected : '%v' != '%v'", i, err, ErrIntegrity)
+		}
+	}
+}
+
+func TestKeyWrapPad(t *testing.T) {
+	cases := []struct {
+		kek      []byte
+		key      []byte
+		expected []byte
+	}{
+		// RFC Section 6 wrap 20 octets with a 192-bit KEK
+		{
+			[]byte{
+				0x58, 0x40, 0xdf, 0x6e, 0x29, 0xb0, 0x2a, 0xf1, 0xab, 0x49, 0x3b, 0x70, 0x5b, 0xf1, 0x6e, 0xa1,
+				0xae, 0x83, 0x38, 0xf4, 0xdc, 0xc1, 0x76, 0xa8,
+			},
+			[]byte{
+				0xc3, 0x7b, 0x7e, 0x64, 0x92, 0x58, 0x43, 0x40, 0xbe, 0xd1, 0x22, 0x07, 0x80, 0x89, 0x41, 0x15,
+				0x50, 0x68, 0xf7, 0x38,
+			},
+			[]byte{
+				0x13, 0x8b, 0xde, 0xaa, 0x9b, 0x8f, 0xa7, 0xfc, 0x61, 0xf9, 0x77, 0x42, 0xe7, 0x22, 0x48, 0xee,
+				0x5a, 0xe6, 0xae, 0x53, 0x60, 0xd1, 0xae, 0x6a, 0x5f, 0x54, 0xf3, 0x73, 0xfa, 0x54, 0x3b, 0x6a,
+			},
+		},
+		// RFC Section 6 wrap 7 octets with a 192-bit KEK
+		{
+			[]byte{
+				0x58, 0x40, 0xdf, 0x6e, 0x29, 0xb0, 0x2a, 0xf1, 0xab, 0x49, 0x3b, 0x70, 0x5b, 0xf1, 0x6e, 0xa1,
+				0xae, 0x83, 0x38, 0xf4, 0xdc, 0xc1, 0x76, 0xa8,
+			},
+			[]byte{0x46, 0x6f, 0x72, 0x50, 0x61, 0x73, 0x69},
+			[]byte{0xaf, 0xbe, 0xb0, 0xf0, 0x7d, 0xfb, 0xf5, 0x41, 0x92, 0x00, 0xf2, 0xcc, 0xb5, 0x0b, 0xb2, 0x4f},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.kek)
+		if err != nil {
+			t.Fatalf("[TestKeyWrapPad] case %d failed: %v", i, err)
+		}
+
+		wrapped, err := WrapPad(b, c.key)
+		if err != nil {
+			t.Errorf("[TestKeyWrapPad] case %d failed: %v", i, err)
+		} else if !bytes.Equal(wrapped, c.expected) {
+			t.Errorf("[TestKeyWrapPad] case %d failed: wrapped != expected :\nwrapped:\t%s\nexpected:\t%s", i, PrintableBytes(wrapped), PrintableBytes(c.expected))
+		}
+
+		unwrapped, err := UnwrapPad(b, c.expected)
+		if err != nil {
+			t.Errorf("[TestKeyWrapPad] case %d failed: %v", i, err)
+		} else if !bytes.Equal(unwrapped, c.key) {
+			t.Errorf("[TestKeyWrapPad] case %d failed: unwrapped != expected :\nunwrapped:\t%s\nexpected:\t%s", i, PrintableBytes(unwrapped), PrintableBytes(c.key))
+		}
+
+		tampered := append([]byte{}, c.expected...)
+		tampered[len(tampered)-1] ^= 0x01
+		if _, err := UnwrapPad(b, tampered); !errors.Is(err, ErrIntegrity) {
+			t.Errorf("[TestKeyWrapPad] case %d failed: err != expected : '%v' != '%v'", i, err, ErrIntegrity)
+		}
+	}
+}
+
+func TestKeyWrapLength(t *testing.T) {
+	b, err := NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("[TestKeyWrapLength] failed: %v", err)
+	}
+
+	for _, n := range []int{0, 8, 17} {
+		if _, err := Wrap(b, make([]byte, n)); !errors.Is(err, ErrPlaintextLength) {
+			t.Errorf("[TestKeyWrapLength] Wrap length %d failed: err != expected : '%v' != '%v'", n, err, ErrPlaintextLength)
+		}
+	}
+	for _, n := range []int{0, 16, 25} {
+		if _, err := Unwrap(b, make([]byte, n)); !errors.Is(err, ErrCiphertextLength) {
+			t.Errorf("[TestKeyWrapLength] Unwrap length %d failed: err != expected : '%v' != '%v'", n, err, ErrCiphertextLength)
+		}
+	}
+	if _, err := WrapPad(b, nil); !errors.Is(err, ErrPlaintextLength) {
+		t.Errorf("[TestKeyWrapLength] WrapPad length 0 failed: err != expected : '%v' != '%v'", err, ErrPlaintextLength)
+	}
+	for _, n := range []int{0, 8, 17} {
+		if _, err := UnwrapPad(b, make([]byte, n)); !errors.Is(err, ErrCiphertextLength) {
+			t.Errorf("[TestKeyWrapLength] UnwrapPad length %d failed: err != expected : '%v' != '%v'", n, err, ErrCiphertextLength)
+		}
+	}
+
+	// key wrapped with RFC 3394 must not be accepted by UnwrapPad
+	wrapped, err := Wrap(b, make([]byte, 16))
+	if err != nil {
+		t.Fatalf("[TestKeyWrapLength] failed: %v", err)
+	}
+	if _, err := UnwrapPad(b, wrapped); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("[TestKeyWrapLength] failed: err != expected : '%v' != '%v'", err, ErrIntegrity)
+	}
+}
+
+func TestCMAC(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	msg := []byte{
+		0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+		0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+		0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+		0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10,
+	}
+	cases := []struct {
+		length   int
+		expected []byte
+	}{
+		// RFC 4493 Section 4
+		{0, []byte{0xbb, 0x1d, 0x69, 0x29, 0xe9, 0x59, 0x37, 0x28, 0x7f, 0xa3, 0x7d, 0x12, 0x9b, 0x75, 0x67, 0x46}},
+		{16, []byte{0x07, 0x0a, 0x16, 0xb4, 0x6b, 0x4d, 0x41, 0x44, 0xf7, 0x9b, 0xdd, 0x9d, 0xd0, 0x4a, 0x28, 0x7c}},
+		{40, []byte{0xdf, 0xa6, 0x67, 0x47, 0xde, 0x9a, 0xe6, 0x30, 0x30, 0xca, 0x32, 0x61, 0x14, 0x97, 0xc8, 0x27}},
+		{64, []byte{0x51, 0xf0, 0xbe, 0xbf, 0x7e, 0x3b, 0x9d, 0x92, 0xfc, 0x49, 0x74, 0x17, 0x79, 0x36, 0x3c, 0xfe}},
+	}
+
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestCMAC] failed: %v", err)
+	}
+	expectedK1 := []byte{0xfb, 0xee, 0xd6, 0x18, 0x35, 0x71, 0x33, 0x66, 0x7c, 0x85, 0xe0, 0x8f, 0x72, 0x36, 0xa8, 0xde}
+	expectedK2 := []byte{0xf7, 0xdd, 0xac, 0x30, 0x6a, 0xe2, 0x66, 0xcc, 0xf9, 0x0b, 0xc1, 0x1e, 0xe4, 0x6d, 0x51, 0x3b}
+	c, err := NewCMAC(b, 16)
+	if err != nil {
+		t.Fatalf("[TestCMAC] failed: %v", err)
+	}
+	if !bytes.Equal(c.k1, expectedK1) || !bytes.Equal(c.k2, expectedK2) {
+		t.Errorf("[TestCMAC] subkey failed: K1, K2 != expected :\nK1:\t%s\nK2:\t%s", PrintableBytes(c.k1), PrintableBytes(c.k2))
+	}
+
+	truncated, err := NewCMAC(b, 8)
+	if err != nil {
+		t.Fatalf("[TestCMAC] failed: %v", err)
+	}
+	for i, tc := range cases {
+		c.Reset()
+		c.Write(msg[:tc.length])
+		if sum := c.Sum(nil); !bytes.Equal(sum, tc.expected) {
+			t.Errorf("[TestCMAC] case %d failed: sum != expected :\nsum:\t\t%s\nexpected:\t%s", i, PrintableBytes(sum), PrintableBytes(tc.expected))
+		}
+
+		// write byte by byte
+		c.Reset()
+		for j := 0; j < tc.length; j++ {
+			c.Write(msg[j : j+1])
+		}
+		if sum := c.Sum(nil); !bytes.Equal(sum, tc.expected) {
+			t.Errorf("[TestCMAC] case %d failed: sum != expected :\nsum:\t\t%s\nexpected:\t%s", i, PrintableBytes(sum), PrintableBytes(tc.expected))
+		}
+
+		truncated.Reset()
+		truncated.Write(msg[:tc.length])
+		if sum := truncated.Sum(nil); !bytes.Equal(sum, tc.expected[:8]) {
+			t.Errorf("[TestCMAC] case %d failed: sum != expected :\nsum:\t\t%s\nexpected:\t%s", i, PrintableBytes(sum), PrintableBytes(tc.expected[:8]))
+		}
+	}
+
+	for _, tagSize := range []int{0, 17} {
+		if _, err := NewCMAC(b, tagSize); !errors.Is(err, ErrTagSize) {
+			t.Errorf("[TestCMAC] tag size %d failed: err != expected : '%v' != '%v'", tagSize, err, ErrTagSize)
+		}
+	}
+}
+
+func TestCMACPRF128(t *testing.T) {
+	msg := []byte{
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+		0x10, 0x11, 0x12, 0x13,
+	}
+	cases := []struct {
+		key      []byte
+		expected []byte
+	}{
+		// RFC 4615 Section 4
+		{
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0xed, 0xcb,
+			},
+			[]byte{0x84, 0xa3, 0x48, 0xa4, 0xa4, 0x5d, 0x23, 0x5b, 0xab, 0xff, 0xfc, 0x0d, 0x2b, 0x4d, 0xa0, 0x9a},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+			[]byte{0x98, 0x0a, 0xe8, 0x7b, 0x5f, 0x4c, 0x9c, 0x52, 0x14, 0xf5, 0xb6, 0xa8, 0x45, 0x5e, 0x4c, 0x2d},
+		},
+		{
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09},
+			[]byte{0x29, 0x0d, 0x9e, 0x11, 0x2e, 0xdb, 0x09, 0xee, 0x14, 0x1f, 0xcf, 0x64, 0xc0, 0xb7, 0x2f, 0x3d},
+		},
+	}
+
+	for i, c := range cases {
+		prf, err := CMACPRF128(c.key, msg)
+		if err != nil {
+			t.Errorf("[TestCMACPRF128] case %d failed: %v", i, err)
+		} else if !bytes.Equal(prf, c.expected) {
+			t.Errorf("[TestCMACPRF128] case %d failed: prf != expected :\nprf:\t\t%s\nexpected:\t%s", i, PrintableBytes(prf), PrintableBytes(c.expected))
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	iv := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	modes := []int{ModeECB, ModeCBC, ModeCFB, ModeCFB8, ModeCFB1, ModeOFB, ModeCTR}
+	lengths := []int{1, 15, 16, 17, 31, 32, 100, 5000}
+
+	for _, mode := range modes {
+		for _, length := range lengths {
+			plain := make([]byte, length)
+			for i := range plain {
+				plain[i] = byte(i*7 + 0x20)
+			}
+			expected, err := Cipher(plain, key, mode, iv, nil)
+			if err != nil {
+				t.Fatalf("[TestStream] mode %d length %d failed: %v", mode, length, err)
+			}
+
+			// write in uneven chunks
+			var buf bytes.Buffer
+			w, err := NewEncryptWriter(&buf, key, mode, iv, nil)
+			if err != nil {
+				t.Fatalf("[TestStream] mode %d length %d failed: %v", mode, length, err)
+			}
+			for from := 0; from < length; from += 7 {
+				to := from + 7
+				if to > length {
+					to = length
+				}
+				if _, err := w.Write(plain[from:to]); err != nil {
+					t.Fatalf("[TestStream] mode %d length %d failed: %v", mode, length, err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("[TestStream] mode %d length %d failed: %v", mode, length, err)
+			}
+			if !bytes.Equal(buf.Bytes(), expected) {
+				t.Errorf("[TestStream] mode %d length %d failed: encrypted != expected :\nencrypted:\t%s\nexpected:\t%s", mode, length, PrintableBytes(buf.Bytes()), PrintableBytes(expected))
+			}
+
+			r, err := NewDecryptReader(iotest.OneByteReader(bytes.NewReader(expected)), key, mode, iv, nil)
+			if err != nil {
+				t.Fatalf("[TestStream] mode %d length %d failed: %v", mode, length, err)
+			}
+			decrypted, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Errorf("[TestStream] mode %d length %d failed: %v", mode, length, err)
+			} else if !bytes.Equal(decrypted, plain) {
+				t.Errorf("[TestStream] mode %d length %d failed: decrypted != expected :\ndecrypted:\t%s\nexpected:\t%s", mode, length, PrintableBytes(decrypted), PrintableBytes(plain))
+			}
+		}
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	key := make([]byte, 16)
+	iv := make([]byte, 16)
+
+	if _, err := NewEncryptWriter(ioutil.Discard, key, ModeGCM, iv, nil); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("[TestStreamErrors] failed: err != expected : '%v' != '%v'", err, ErrInvalidMode)
+	}
+	if _, err := NewDecryptReader(bytes.NewReader(nil), key, ModeCTR, iv[:8], nil); !errors.Is(err, ErrIVSize) {
+		t.Errorf("[TestStreamErrors] failed: err != expected : '%v' != '%v'", err, ErrIVSize)
+	}
+	if _, err := NewEncryptWriter(ioutil.Discard, key[:10], ModeCBC, iv, nil); !errors.Is(err, ErrKeySize) {
+		t.Errorf("[TestStreamErrors] failed: err != expected : '%v' != '%v'", err, ErrKeySize)
+	}
+
+	for _, length := range []int{0, 17} {
+		r, err := NewDecryptReader(bytes.NewReader(make([]byte, length)), key, ModeCBC, iv, nil)
+		if err != nil {
+			t.Fatalf("[TestStreamErrors] length %d failed: %v", length, err)
+		}
+		if _, err := ioutil.ReadAll(r); !errors.Is(err, ErrCiphertextLength) {
+			t.Errorf("[TestStreamErrors] length %d failed: err != expected : '%v' != '%v'", length, err, ErrCiphertextLength)
+		}
+	}
+}
+
+func TestCFBSegment(t *testing.T) {
+	iv := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	cases := []struct {
+		key         []byte
+		segmentSize int
+		plain       []byte
+		expected    []byte
+	}{
+		// NIST SP 800-38A F.3.1, F.3.3, F.3.5 CFB1
+		{
+			[]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c},
+			1,
+			[]byte{0x6b, 0xc1},
+			[]byte{0x68, 0xb3},
+		},
+		{
+			[]byte{
+				0x8e, 0x73, 0xb0, 0xf7, 0xda, 0x0e, 0x64, 0x52, 0xc8, 0x10, 0xf3, 0x2b, 0x80, 0x90, 0x79, 0xe5,
+				0x62, 0xf8, 0xea, 0xd2, 0x52, 0x2c, 0x6b, 0x7b,
+			},
+			1,
+			[]byte{0x6b, 0xc1},
+			[]byte{0x93, 0x59},
+		},
+		{
+			[]byte{
+				0x60, 0x3d, 0xeb, 0x10, 0x15, 0xca, 0x71, 0xbe, 0x2b, 0x73, 0xae, 0xf0, 0x85, 0x7d, 0x77, 0x81,
+				0x1f, 0x35, 0x2c, 0x07, 0x3b, 0x61, 0x08, 0xd7, 0x2d, 0x98, 0x10, 0xa3, 0x09, 0x14, 0xdf, 0xf4,
+			},
+			1,
+			[]byte{0x6b, 0xc1},
+			[]byte{0x90, 0x29},
+		},
+		// NIST SP 800-38A F.3.7, F.3.9, F.3.11 CFB8
+		{
+			[]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c},
+			8,
+			[]byte{
+				0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d,
+			},
+			[]byte{
+				0x3b, 0x79, 0x42, 0x4c, 0x9c, 0x0d, 0xd4, 0x36, 0xba, 0xce, 0x9e, 0x0e, 0xd4, 0x58, 0x6a, 0x4f,
+				0x32, 0xb9,
+			},
+		},
+		{
+			[]byte{
+				0x8e, 0x73, 0xb0, 0xf7, 0xda, 0x0e, 0x64, 0x52, 0xc8, 0x10, 0xf3, 0x2b, 0x80, 0x90, 0x79, 0xe5,
+				0x62, 0xf8, 0xea, 0xd2, 0x52, 0x2c, 0x6b, 0x7b,
+			},
+			8,
+			[]byte{
+				0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d,
+			},
+			[]byte{
+				0xcd, 0xa2, 0x52, 0x1e, 0xf0, 0xa9, 0x05, 0xca, 0x44, 0xcd, 0x05, 0x7c, 0xbf, 0x0d, 0x47, 0xa0,
+				0x67, 0x8a,
+			},
+		},
+		{
+			[]byte{
+				0x60, 0x3d, 0xeb, 0x10, 0x15, 0xca, 0x71, 0xbe, 0x2b, 0x73, 0xae, 0xf0, 0x85, 0x7d, 0x77, 0x81,
+				0x1f, 0x35, 0x2c, 0x07, 0x3b, 0x61, 0x08, 0xd7, 0x2d, 0x98, 0x10, 0xa3, 0x09, 0x14, 0xdf, 0xf4,
+			},
+			8,
+			[]byte{
+				0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d,
+			},
+			[]byte{
+				0xdc, 0x1f, 0x1a, 0x85, 0x20, 0xa6, 0x4d, 0xb5, 0x5f, 0xcc, 0x8a, 0xc5, 0x54, 0x84, 0x4e, 0x88,
+				0x97, 0x00,
+			},
+		},
+		// NIST SP 800-38A F.3.13, F.3.15, F.3.17 CFB128
+		{
+			[]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c},
+			128,
+			[]byte{
+				0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+				0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+				0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10,
+			},
+			[]byte{
+				0x3b, 0x3f, 0xd9, 0x2e, 0xb7, 0x2d, 0xad, 0x20, 0x33, 0x34, 0x49, 0xf8, 0xe8, 0x3c, 0xfb, 0x4a,
+				0xc8, 0xa6, 0x45, 0x37, 0xa0, 0xb3, 0xa9, 0x3f, 0xcd, 0xe3, 0xcd, 0xad, 0x9f, 0x1c, 0xe5, 0x8b,
+				0x26, 0x75, 0x1f, 0x67, 0xa3, 0xcb, 0xb1, 0x40, 0xb1, 0x80, 0x8c, 0xf1, 0x87, 0xa4, 0xf4, 0xdf,
+				0xc0, 0x4b, 0x05, 0x35, 0x7c, 0x5d, 0x1c, 0x0e, 0xea, 0xc4, 0xc6, 0x6f, 0x9f, 0xf7, 0xf2, 0xe6,
+			},
+		},
+		{
+			[]byte{
+				0x8e, 0x73, 0xb0, 0xf7, 0xda, 0x0e, 0x64, 0x52, 0xc8, 0x10, 0xf3, 0x2b, 0x80, 0x90, 0x79, 0xe5,
+				0x62, 0xf8, 0xea, 0xd2, 0x52, 0x2c, 0x6b, 0x7b,
+			},
+			128,
+			[]byte{
+				0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+				0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+				0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10,
+			},
+			[]byte{
+				0xcd, 0xc8, 0x0d, 0x6f, 0xdd, 0xf1, 0x8c, 0xab, 0x34, 0xc2, 0x59, 0x09, 0xc9, 0x9a, 0x41, 0x74,
+				0x67, 0xce, 0x7f, 0x7f, 0x81, 0x17, 0x36, 0x21, 0x96, 0x1a, 0x2b, 0x70, 0x17, 0x1d, 0x3d, 0x7a,
+				0x2e, 0x1e, 0x8a, 0x1d, 0xd5, 0x9b, 0x88, 0xb1, 0xc8, 0xe6, 0x0f, 0xed, 0x1e, 0xfa, 0xc4, 0xc9,
+				0xc0, 0x5f, 0x9f, 0x9c, 0xa9, 0x83, 0x4f, 0xa0, 0x42, 0xae, 0x8f, 0xba, 0x58, 0x4b, 0x09, 0xff,
+			},
+		},
+		{
+			[]byte{
+				0x60, 0x3d, 0xeb, 0x10, 0x15, 0xca, 0x71, 0xbe, 0x2b, 0x73, 0xae, 0xf0, 0x85, 0x7d, 0x77, 0x81,
+				0x1f, 0x35, 0x2c, 0x07, 0x3b, 0x61, 0x08, 0xd7, 0x2d, 0x98, 0x10, 0xa3, 0x09, 0x14, 0xdf, 0xf4,
+			},
+			128,
+			[]byte{
+				0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+				0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+				0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10,
+			},
+			[]byte{
+				0xdc, 0x7e, 0x84, 0xbf, 0xda, 0x79, 0x16, 0x4b, 0x7e, 0xcd, 0x84, 0x86, 0x98, 0x5d, 0x38, 0x60,
+				0x39, 0xff, 0xed, 0x14, 0x3b, 0x28, 0xb1, 0xc8, 0x32, 0x11, 0x3c, 0x63, 0x31, 0xe5, 0x40, 0x7b,
+				0xdf, 0x10, 0x13, 0x24, 0x15, 0xe5, 0x4b, 0x92, 0xa1, 0x3e, 0xd0, 0xa8, 0x26, 0x7a, 0xe2, 0xf9,
+				0x75, 0xa3, 0x85, 0x74, 0x1a, 0xb9, 0xce, 0xf8, 0x20, 0x31, 0x62, 0x3d, 0x55, 0xb1, 0xe4, 0x71,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		b, err := NewCipher(c.key)
+		if err != nil {
+			t.Fatalf("[TestCFBSegment] case %d failed: %v", i, err)
+		}
+
+		cipherText, err := CFBSegmentCipher(b, c.plain, iv, c.segmentSize)
+		if err != nil {
+			t.Errorf("[TestCFBSegment] case %d failed: %v", i, err)
+		} else if !bytes.Equal(cipherText, c.expected) {
+			t.Errorf("[TestCFBSegment] case %d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", i, PrintableBytes(cipherText), PrintableBytes(c.expected))
+		}
+
+		plainText, err := CFBSegmentInvCipher(b, c.expected, iv, c.segmentSize)
+		if err != nil {
+			t.Errorf("[TestCFBSegment] case %d failed: %v", i, err)
+		} else if !bytes.Equal(plainText, c.plain) {
+			t.Errorf("[TestCFBSegment] case %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", i, PrintableBytes(plainText), PrintableBytes(c.plain))
+		}
+	}
+}
+
+func TestCFBSegmentMode(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	iv := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	plainText := []byte("segment size of CFB mode")
+
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestCFBSegmentMode] failed: %v", err)
+	}
+	// CFB-128 with partial last segment is the same as CFBCipher
+	expected, err := CFBCipher(b, plainText, iv)
+	if err != nil {
+		t.Fatalf("[TestCFBSegmentMode] failed: %v", err)
+	}
+	if cipherText, err := CFBSegmentCipher(b, plainText, iv, 128); err != nil || !bytes.Equal(cipherText, expected) {
+		t.Errorf("[TestCFBSegmentMode] failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", PrintableBytes(cipherText), PrintableBytes(expected))
+	}
+
+	for _, mode := range []int{ModeCFB8, ModeCFB1} {
+		cipherText, err := Cipher(plainText, key, mode, iv, nil)
+		if err != nil {
+			t.Fatalf("[TestCFBSegmentMode] mode %d failed: %v", mode, err)
+		}
+		decrypted, err := InvCipher(cipherText, key, mode, iv, nil)
+		if err != nil {
+			t.Errorf("[TestCFBSegmentMode] mode %d failed: %v", mode, err)
+		} else if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestCFBSegmentMode] mode %d failed: decrypted != expected :\ndecrypted:\t%s\nexpected:\t%s", mode, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+	}
+
+	// CFB-64 round trip
+	cipherText, err := CFBSegmentCipher(b, plainText, iv, 64)
+	if err != nil {
+		t.Fatalf("[TestCFBSegmentMode] failed: %v", err)
+	}
+	if decrypted, err := CFBSegmentInvCipher(b, cipherText, iv, 64); err != nil || !bytes.Equal(decrypted, plainText) {
+		t.Errorf("[TestCFBSegmentMode] failed: decrypted != expected :\ndecrypted:\t%s\nexpected:\t%s", PrintableBytes(decrypted), PrintableBytes(plainText))
+	}
+
+	for _, segmentSize := range []int{0, 2, 12, 136} {
+		if _, err := CFBSegmentCipher(b, plainText, iv, segmentSize); !errors.Is(err, ErrSegmentSize) {
+			t.Errorf("[TestCFBSegmentMode] segment size %d failed: err != expected : '%v' != '%v'", segmentSize, err, ErrSegmentSize)
+		}
+	}
+}
+
+func TestCTRConfig(t *testing.T) {
+	plainText := []byte{
+		0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+		0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+		0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+		0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10,
+	}
+	cases := []struct {
+		key      []byte
+		iv       []byte
+		expected []byte
+	}{
+		// NIST SP 800-38A F.5.1 CTR-AES128.Encrypt
+		{
+			[]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c},
+			[]byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff},
+			[]byte{
+				0x87, 0x4d, 0x61, 0x91, 0xb6, 0x20, 0xe3, 0x26, 0x1b, 0xef, 0x68, 0x64, 0x99, 0x0d, 0xb6, 0xce,
+				0x98, 0x06, 0xf6, 0x6b, 0x79, 0x70, 0xfd, 0xff, 0x86, 0x17, 0x18, 0x7b, 0xb9, 0xff, 0xfd, 0xff,
+				0x5a, 0xe4, 0xdf, 0x3e, 0xdb, 0xd5, 0xd3, 0x5e, 0x5b, 0x4f, 0x09, 0x02, 0x0d, 0xb0, 0x3e, 0xab,
+				0x1e, 0x03, 0x1d, 0xda, 0x2f, 0xbe, 0x03, 0xd1, 0x79, 0x21, 0x70, 0xa0, 0xf3, 0x00, 0x9c, 0xee,
+			},
+		},
+		// NIST SP 800-38A F.5.3 CTR-AES192.Encrypt
+		{
+			[]byte{
+				0x8e, 0x73, 0xb0, 0xf7, 0xda, 0x0e, 0x64, 0x52, 0xc8, 0x10, 0xf3, 0x2b, 0x80, 0x90, 0x79, 0xe5,
+				0x62, 0xf8, 0xea, 0xd2, 0x52, 0x2c, 0x6b, 0x7b,
+			},
+			[]byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff},
+			[]byte{
+				0x1a, 0xbc, 0x93, 0x24, 0x17, 0x52, 0x1c, 0xa2, 0x4f, 0x2b, 0x04, 0x59, 0xfe, 0x7e, 0x6e, 0x0b,
+				0x09, 0x03, 0x39, 0xec, 0x0a, 0xa6, 0xfa, 0xef, 0xd5, 0xcc, 0xc2, 0xc6, 0xf4, 0xce, 0x8e, 0x94,
+				0x1e, 0x36, 0xb2, 0x6b, 0xd1, 0xeb, 0xc6, 0x70, 0xd1, 0xbd, 0x1d, 0x66, 0x56, 0x20, 0xab, 0xf7,
+				0x4f, 0x78, 0xa7, 0xf6, 0xd2, 0x98, 0x09, 0x58, 0x5a, 0x97, 0xda, 0xec, 0x58, 0xc6, 0xb0, 0x50,
+			},
+		},
+		// NIST SP 800-38A F.5.5 CTR-AES256.Encrypt
+		{
+			[]byte{
+				0x60, 0x3d, 0xeb, 0x10, 0x15, 0xca, 0x71, 0xbe, 0x2b, 0x73, 0xae, 0xf0, 0x85, 0x7d, 0x77, 0x81,
+				0x1f, 0x35, 0x2c, 0x07, 0x3b, 0x61, 0x08, 0xd7, 0x2d, 0x98, 0x10, 0xa3, 0x09, 0x14, 0xdf, 0xf4,
+			},
+			[]byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff},
+			[]byte{
+				0x60, 0x1e, 0xc3, 0x13, 0x77, 0x57, 0x89, 0xa5, 0xb7, 0xa7, 0xf5, 0x04, 0xbb, 0xf3, 0xd2, 0x28,
+				0xf4, 0x43, 0xe3, 0xca, 0x4d, 0x62, 0xb5, 0x9a, 0xca, 0x84, 0xe9, 0x90, 0xca, 0xca, 0xf5, 0xc5,
+				0x2b, 0x09, 0x30, 0xda, 0xa2, 0x3d, 0xe9, 0x4c, 0xe8, 0x70, 0x17, 0xba, 0x2d, 0x84, 0x98, 0x8d,
+				0xdf, 0xc9, 0xc5, 0x8d, 0xb6, 0x7a, 0xad, 0xa6, 0x13, 0xc2, 0xdd, 0x08, 0x45, 0x79, 0x41, 0xa6,
+			},
+		},
+		// carry beyond 64 bit
+		{
+			[]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe},
+			[]byte{
+				0x80, 0xd9, 0xf9, 0xcd, 0xdc, 0x6c, 0x8d, 0x50, 0xd1, 0xf8, 0xcc, 0xf6, 0x5b, 0xbe, 0x1a, 0x0a,
+				0x93, 0xa5, 0x2c, 0xda, 0xae, 0xf0, 0x4f, 0x5a, 0xf0, 0xc8, 0xb7, 0x6d, 0xf4, 0x64, 0xf7, 0x2b,
+				0x1a, 0x40, 0x8d, 0x94, 0x9a, 0xc8, 0x7f, 0xfb, 0xdb, 0x5f, 0x37, 0xd8, 0x65, 0x74, 0xfb, 0xb8,
+				0xfc, 0x4d, 0x52, 0xfc, 0x7b, 0x80, 0x90, 0x02, 0x44, 0x98, 0xe9, 0x8e, 0x9d, 0x96, 0x8b, 0x59,
+			},
+		},
+	}
+
+	// 128 bit counter
+	config := CTRConfig{CounterSize: 16}
+	for i, c := range cases {
+		b, err := NewCipher(c.key)
+		if err != nil {
+			t.Fatalf("[TestCTRConfig] case %d failed: %v", i, err)
+		}
+
+		cipherText, err := CTRCipherWithConfig(b, plainText, c.iv, config)
+		if err != nil {
+			t.Errorf("[TestCTRConfig] case %d failed: %v", i, err)
+		} else if !bytes.Equal(cipherText, c.expected) {
+			t.Errorf("[TestCTRConfig] case %d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", i, PrintableBytes(cipherText), PrintableBytes(c.expected))
+		}
+
+		decrypted, err := CTRInvCipherWithConfig(b, c.expected, c.iv, config)
+		if err != nil {
+			t.Errorf("[TestCTRConfig] case %d failed: %v", i, err)
+		} else if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestCTRConfig] case %d failed: decrypted != expected :\ndecrypted:\t%s\nexpected:\t%s", i, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+
+		// seek to the third block
+		seeked, err := CTRCipherWithConfig(b, plainText[32:], c.iv, CTRConfig{CounterSize: 16, Offset: 2})
+		if err != nil {
+			t.Errorf("[TestCTRConfig] case %d failed: %v", i, err)
+		} else if !bytes.Equal(seeked, c.expected[32:]) {
+			t.Errorf("[TestCTRConfig] case %d failed: seeked != expected :\nseeked:\t\t%s\nexpected:\t%s", i, PrintableBytes(seeked), PrintableBytes(c.expected[32:]))
+		}
+	}
+}
+
+func TestCTRConfigLayout(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestCTRConfigLayout] failed: %v", err)
+	}
+
+	cases := []struct {
+		config   CTRConfig
+		iv       []byte
+		counters []byte // expected counter blocks
+	}{
+		// 32 bit big-endian counter doesn't carry into nonce
+		{
+			CTRConfig{CounterSize: 4},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x00, 0x00, 0x00, 0xff},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x00, 0x00, 0x00, 0xff,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x00, 0x00, 0x01, 0x00,
+			},
+		},
+		// 32 bit little-endian counter
+		{
+			CTRConfig{CounterSize: 4, LittleEndian: true},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0xff, 0x00, 0x00, 0x00},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0xff, 0x00, 0x00, 0x00,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x00, 0x01, 0x00, 0x00,
+			},
+		},
+		// offset is added to the initial counter
+		{
+			CTRConfig{CounterSize: 4, LittleEndian: true, Offset: 0x101},
+			[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0xff, 0x00, 0x00, 0x00},
+			[]byte{
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x00, 0x02, 0x00, 0x00,
+				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x01, 0x02, 0x00, 0x00,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		// key stream is the encrypted counter blocks
+		expected, err := ECBCipher(b, c.counters, NoPadding{})
+		if err != nil {
+			t.Fatalf("[TestCTRConfigLayout] case %d failed: %v", i, err)
+		}
+		keyStream, err := CTRCipherWithConfig(b, make([]byte, len(c.counters)), c.iv, c.config)
+		if err != nil {
+			t.Errorf("[TestCTRConfigLayout] case %d failed: %v", i, err)
+		} else if !bytes.Equal(keyStream, expected) {
+			t.Errorf("[TestCTRConfigLayout] case %d failed: keyStream != expected :\nkeyStream:\t%s\nexpected:\t%s", i, PrintableBytes(keyStream), PrintableBytes(expected))
+		}
+	}
+}
+
+func TestCTRCounterOverflow(t *testing.T) {
+	key := make([]byte, 16)
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestCTRCounterOverflow] failed: %v", err)
+	}
+	iv := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+
+	cases := []struct {
+		config   CTRConfig
+		length   int
+		expected error
+	}{
+		{CTRConfig{}, 32, nil},
+		{CTRConfig{}, 33, ErrCounterOverflow},
+		{CTRConfig{CounterSize: 4}, 33, ErrCounterOverflow},
+		{CTRConfig{CounterSize: 16}, 33, nil},
+		{CTRConfig{Offset: 1}, 16, nil},
+		{CTRConfig{Offset: 2}, 1, ErrCounterOverflow},
+		{CTRConfig{CounterSize: 17}, 16, ErrCounterSize},
+		{CTRConfig{CounterSize: -1}, 16, ErrCounterSize},
+	}
+	for i, c := range cases {
+		if _, err := CTRCipherWithConfig(b, make([]byte, c.length), iv, c.config); !errors.Is(err, c.expected) {
+			t.Errorf("[TestCTRCounterOverflow] case %d failed: err != expected : '%v' != '%v'", i, err, c.expected)
+		}
+	}
+
+	// CTRCipher and streaming don't wrap 64 bit counter
+	if _, err := Cipher(make([]byte, 33), key, ModeCTR, iv, nil); !errors.Is(err, ErrCounterOverflow) {
+		t.Errorf("[TestCTRCounterOverflow] failed: err != expected : '%v' != '%v'", err, ErrCounterOverflow)
+	}
+	w, err := NewEncryptWriter(ioutil.Discard, key, ModeCTR, iv, nil)
+	if err != nil {
+		t.Fatalf("[TestCTRCounterOverflow] failed: %v", err)
+	}
+	if _, err := w.Write(make([]byte, 33)); !errors.Is(err, ErrCounterOverflow) {
+		t.Errorf("[TestCTRCounterOverflow] failed: err != expected : '%v' != '%v'", err, ErrCounterOverflow)
+	}
+}
+
+func TestTTable(t *testing.T) {
+	keys := [][]byte{
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+			0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+		},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+			0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+		},
+	}
+	plainText := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	// FIPS-197 Appendix C
+	expected := [][]byte{
+		[]byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a},
+		[]byte{0xdd, 0xa9, 0x7c, 0xa4, 0x86, 0x4c, 0xdf, 0xe0, 0x6e, 0xaf, 0x70, 0xa0, 0xec, 0x0d, 0x71, 0x91},
+		[]byte{0x8e, 0xa2, 0xb7, 0xca, 0x51, 0x67, 0x45, 0xbf, 0xea, 0xfc, 0x49, 0x90, 0x4b, 0x49, 0x60, 0x89},
+	}
+
+	for i, key := range keys {
+		b, err := NewCipherWithImplementation(key, ImplementationTTable)
+		if err != nil {
+			t.Fatalf("[TestTTable] case %d failed: %v", i, err)
+		}
+		ref, err := NewCipher(key)
+		if err != nil {
+			t.Fatalf("[TestTTable] case %d failed: %v", i, err)
+		}
+
+		cipherText := make([]byte, 16)
+		b.Encrypt(cipherText, plainText)
+		if !bytes.Equal(cipherText, expected[i]) {
+			t.Errorf("[TestTTable] case %d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", i, PrintableBytes(cipherText), PrintableBytes(expected[i]))
+		}
+		decrypted := make([]byte, 16)
+		b.Decrypt(decrypted, expected[i])
+		if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestTTable] case %d failed: decrypted != expected :\ndecrypted:\t%s\nexpected:\t%s", i, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+
+		// T-table implementation must be the same as the reference implementation
+		in := make([]byte, 16)
+		copy(in, plainText)
+		for j := 0; j < 100; j++ {
+			out := make([]byte, 16)
+			refOut := make([]byte, 16)
+			b.Encrypt(out, in)
+			ref.Encrypt(refOut, in)
+			if !bytes.Equal(out, refOut) {
+				t.Fatalf("[TestTTable] case %d-%d failed: encrypted != reference :\nencrypted:\t%s\nreference:\t%s", i, j, PrintableBytes(out), PrintableBytes(refOut))
+			}
+			b.Decrypt(out, in)
+			ref.Decrypt(refOut, in)
+			if !bytes.Equal(out, refOut) {
+				t.Fatalf("[TestTTable] case %d-%d failed: decrypted != reference :\ndecrypted:\t%s\nreference:\t%s", i, j, PrintableBytes(out), PrintableBytes(refOut))
+			}
+			copy(in, out)
+		}
+	}
+
+	if _, err := NewCipherWithImplementation(keys[0], Implementation(-1)); !errors.Is(err, ErrInvalidImplementation) {
+		t.Errorf("[TestTTable] failed: err != expected : '%v' != '%v'", err, ErrInvalidImplementation)
+	}
+}
+
+func BenchmarkModes(b *testing.B) {
+	key := make([]byte, 16)
+	iv := make([]byte, 16)
+	nonce := make([]byte, 12)
+	in := make([]byte, 4096)
+
+	implementations := []struct {
+		name string
+		impl Implementation
+	}{
+		{"Reference", ImplementationReference},
+		{"TTable", ImplementationTTable},
+		{"Bitsliced", ImplementationBitsliced},
+	}
+	modes := []struct {
+		name string
+		fn   func(block *Block) ([]byte, error)
+	}{
+		{"ECB", func(block *Block) ([]byte, error) { return ECBCipher(block, in, NoPadding{}) }},
+		{"ECBInv", func(block *Block) ([]byte, error) { return ECBInvCipher(block, in, NoPadding{}) }},
+		{"CBC", func(block *Block) ([]byte, error) { return CBCCipher(block, in, iv, NoPadding{}) }},
+		{"CBCInv", func(block *Block) ([]byte, error) { return CBCInvCipher(block, in, iv, NoPadding{}) }},
+		{"CFB", func(block *Block) ([]byte, error) { return CFBCipher(block, in, iv) }},
+		{"OFB", func(block *Block) ([]byte, error) { return OFBCipher(block, in, iv) }},
+		{"CTR", func(block *Block) ([]byte, error) { return CTRCipher(block, in, iv) }},
+		{"GCM", func(block *Block) ([]byte, error) { return GCMCipher(block, in, nonce) }},
+	}
+
+	for _, impl := range implementations {
+		block, err := NewCipherWithImplementation(key, impl.impl)
+		if err != nil {
+			b.Fatalf("[BenchmarkModes] failed: %v", err)
+		}
+		for _, mode := range modes {
+			b.Run(impl.name+"/"+mode.name, func(b *testing.B) {
+				b.SetBytes(int64(len(in)))
+				for i := 0; i < b.N; i++ {
+					if _, err := mode.fn(block); err != nil {
+						b.Fatalf("[BenchmarkModes] failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	iv := []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff}
+	b, err := NewCipherWithImplementation(key, ImplementationTTable)
+	if err != nil {
+		t.Fatalf("[TestParallel] failed: %v", err)
+	}
+
+	// sizes around chunk boundary
+	sizes := []int{0, 1, 16, 17, parallelChunkBlocks * 16, parallelChunkBlocks*16 + 5, parallelChunkBlocks*16*5 + 32}
+	for i, size := range sizes {
+		in := make([]byte, size)
+		for j := range in {
+			in[j] = byte(j * 7)
+		}
+
+		expected, err := ECBCipher(b, in, nil)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		out, err := ECBCipherParallel(b, in, nil)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(out, expected) {
+			t.Errorf("[TestParallel] case %d failed: ECB parallel != sequential", i)
+		}
+		decrypted, err := ECBInvCipherParallel(b, out, nil)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(decrypted, in) {
+			t.Errorf("[TestParallel] case %d failed: ECB decrypted != plainText", i)
+		}
+
+		cipherText, err := CBCCipher(b, in, iv, nil)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		decrypted, err = CBCInvCipherParallel(b, cipherText, iv, nil)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(decrypted, in) {
+			t.Errorf("[TestParallel] case %d failed: CBC decrypted != plainText", i)
+		}
+
+		expected, err = CTRCipher(b, in, iv)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		out, err = CTRCipherParallel(b, in, iv)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(out, expected) {
+			t.Errorf("[TestParallel] case %d failed: CTR parallel != sequential", i)
+		}
+
+		config := CTRConfig{CounterSize: 4, LittleEndian: true, Offset: 3}
+		expected, err = CTRCipherWithConfig(b, in, iv, config)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		out, err = CTRCipherWithConfigParallel(b, in, iv, config)
+		if err != nil {
+			t.Fatalf("[TestParallel] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(out, expected) {
+			t.Errorf("[TestParallel] case %d failed: CTR with config parallel != sequential", i)
+		}
+	}
+
+	// counter of the later chunk overflows
+	zeroIV := make([]byte, 16)
+	in := make([]byte, parallelChunkBlocks*16*3)
+	config := CTRConfig{CounterSize: 2, Offset: 0xffff - parallelChunkBlocks*2}
+	if _, err := CTRCipherWithConfigParallel(b, in, zeroIV, config); !errors.Is(err, ErrCounterOverflow) {
+		t.Errorf("[TestParallel] failed: err != expected : '%v' != '%v'", err, ErrCounterOverflow)
+	}
+
+	// errors of parameters
+	if _, err := CBCInvCipherParallel(b, in[:17], iv, nil); !errors.Is(err, ErrCiphertextLength) {
+		t.Errorf("[TestParallel] failed: err != expected : '%v' != '%v'", err, ErrCiphertextLength)
+	}
+	if _, err := CTRCipherParallel(b, in, iv[:8]); !errors.Is(err, ErrIVSize) {
+		t.Errorf("[TestParallel] failed: err != expected : '%v' != '%v'", err, ErrIVSize)
+	}
+}
+
+func BenchmarkParallel(b *testing.B) {
+	key := make([]byte, 16)
+	iv := make([]byte, 16)
+	in := make([]byte, 256*1024)
+
+	block, err := NewCipherWithImplementation(key, ImplementationTTable)
+	if err != nil {
+		b.Fatalf("[BenchmarkParallel] failed: %v", err)
+	}
+	cipherText, err := CBCCipher(block, in, iv, NoPadding{})
+	if err != nil {
+		b.Fatalf("[BenchmarkParallel] failed: %v", err)
+	}
+
+	modes := []struct {
+		name string
+		fn   func() ([]byte, error)
+	}{
+		{"ECB/Sequential", func() ([]byte, error) { return ECBCipher(block, in, NoPadding{}) }},
+		{"ECB/Parallel", func() ([]byte, error) { return ECBCipherParallel(block, in, NoPadding{}) }},
+		{"ECBInv/Sequential", func() ([]byte, error) { return ECBInvCipher(block, in, NoPadding{}) }},
+		{"ECBInv/Parallel", func() ([]byte, error) { return ECBInvCipherParallel(block, in, NoPadding{}) }},
+		{"CBCInv/Sequential", func() ([]byte, error) { return CBCInvCipher(block, cipherText, iv, NoPadding{}) }},
+		{"CBCInv/Parallel", func() ([]byte, error) { return CBCInvCipherParallel(block, cipherText, iv, NoPadding{}) }},
+		{"CTR/Sequential", func() ([]byte, error) { return CTRCipher(block, in, iv) }},
+		{"CTR/Parallel", func() ([]byte, error) { return CTRCipherParallel(block, in, iv) }},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			b.SetBytes(int64(len(in)))
+			for i := 0; i < b.N; i++ {
+				if _, err := mode.fn(); err != nil {
+					b.Fatalf("[BenchmarkParallel] failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRijndael(t *testing.T) {
+	// test vectors are Rijndael reference vectors by B. Gladman
+	// for all combinations of block size and key size
+	key := []byte{
+		0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c,
+		0x76, 0x2e, 0x71, 0x60, 0xf3, 0x8b, 0x4d, 0xa5, 0x6a, 0x78, 0x4d, 0x90, 0x45, 0x19, 0x0c, 0xfe,
+	}
+	plainText := []byte{
+		0x32, 0x43, 0xf6, 0xa8, 0x88, 0x5a, 0x30, 0x8d, 0x31, 0x31, 0x98, 0xa2, 0xe0, 0x37, 0x07, 0x34,
+		0x4a, 0x40, 0x93, 0x82, 0x22, 0x99, 0xf3, 0x1d, 0x00, 0x82, 0xef, 0xa9, 0x8e, 0xc4, 0xe6, 0xc8,
+	}
+	params := []struct {
+		blockSize int
+		keySize   int
+	}{
+		{16, 16},
+		{16, 24},
+		{16, 32},
+		{24, 16},
+		{24, 24},
+		{24, 32},
+		{32, 16},
+		{32, 24},
+		{32, 32},
+	}
+	expected := [][]byte{
+		[]byte{0x39, 0x25, 0x84, 0x1d, 0x02, 0xdc, 0x09, 0xfb, 0xdc, 0x11, 0x85, 0x97, 0x19, 0x6a, 0x0b, 0x32},
+		[]byte{0xf9, 0xfb, 0x29, 0xae, 0xfc, 0x38, 0x4a, 0x25, 0x03, 0x40, 0xd8, 0x33, 0xb8, 0x7e, 0xbc, 0x00},
+		[]byte{0x1a, 0x6e, 0x6c, 0x2c, 0x66, 0x2e, 0x7d, 0xa6, 0x50, 0x1f, 0xfb, 0x62, 0xbc, 0x9e, 0x93, 0xf3},
+		[]byte{
+			0xb2, 0x4d, 0x27, 0x54, 0x89, 0xe8, 0x2b, 0xb8, 0xf7, 0x37, 0x5e, 0x0d, 0x5f, 0xcd, 0xb1, 0xf4,
+			0x81, 0x75, 0x7c, 0x53, 0x8b, 0x65, 0x14, 0x8a,
+		},
+		[]byte{
+			0x72, 0x5a, 0xe4, 0x3b, 0x5f, 0x31, 0x61, 0xde, 0x80, 0x6a, 0x7c, 0x93, 0xe0, 0xbc, 0xa9, 0x3c,
+			0x96, 0x7e, 0xc1, 0xae, 0x1b, 0x71, 0xe1, 0xcf,
+		},
+		[]byte{
+			0x0e, 0xba, 0xcf, 0x19, 0x9e, 0x33, 0x15, 0xc2, 0xe3, 0x4b, 0x24, 0xfc, 0xc7, 0xc4, 0x6e, 0xf4,
+			0x38, 0x8a, 0xa4, 0x75, 0xd6, 0x6c, 0x19, 0x4c,
+		},
+		[]byte{
+			0x7d, 0x15, 0x47, 0x90, 0x76, 0xb6, 0x9a, 0x46, 0xff, 0xb3, 0xb3, 0xbe, 0xae, 0x97, 0xad, 0x83,
+			0x13, 0xf6, 0x22, 0xf6, 0x7f, 0xed, 0xb4, 0x87, 0xde, 0x9f, 0x06, 0xb9, 0xed, 0x9c, 0x8f, 0x19,
+		},
+		[]byte{
+			0x5d, 0x71, 0x01, 0x72, 0x7b, 0xb2, 0x57, 0x81, 0xbf, 0x67, 0x15, 0xb0, 0xe6, 0x95, 0x52, 0x82,
+			0xb9, 0x61, 0x0e, 0x23, 0xa4, 0x3c, 0x2e, 0xb0, 0x62, 0x69, 0x9f, 0x0e, 0xbf, 0x58, 0x87, 0xb2,
+		},
+		[]byte{
+			0xa4, 0x94, 0x06, 0x11, 0x5d, 0xfb, 0x30, 0xa4, 0x04, 0x18, 0xaa, 0xfa, 0x48, 0x69, 0xb7, 0xc6,
+			0xa8, 0x86, 0xff, 0x31, 0x60, 0x2a, 0x7d, 0xd1, 0x9c, 0x88, 0x9d, 0xc6, 0x4f, 0x7e, 0x4e, 0x7a,
+		},
+	}
+
+	implementations := []Implementation{ImplementationReference, ImplementationTTable, ImplementationBitsliced}
+	for _, impl := range implementations {
+		for i, param := range params {
+			b, err := NewRijndaelWithImplementation(key[:param.keySize], param.blockSize, impl)
+			if err != nil {
+				t.Fatalf("[TestRijndael] case %d-%d failed: %v", impl, i, err)
+			}
+			if b.BlockSize() != param.blockSize {
+				t.Errorf("[TestRijndael] case %d-%d failed: block size != expected : '%d' != '%d'", impl, i, b.BlockSize(), param.blockSize)
+			}
+
+			cipherText := make([]byte, param.blockSize)
+			b.Encrypt(cipherText, plainText[:param.blockSize])
+			if !bytes.Equal(cipherText, expected[i]) {
+				t.Errorf("[TestRijndael] case %d-%d failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", impl, i, PrintableBytes(cipherText), PrintableBytes(expected[i]))
+			}
+			decrypted := make([]byte, param.blockSize)
+			b.Decrypt(decrypted, expected[i])
+			if !bytes.Equal(decrypted, plainText[:param.blockSize]) {
+				t.Errorf("[TestRijndael] case %d-%d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", impl, i, PrintableBytes(decrypted), PrintableBytes(plainText[:param.blockSize]))
+			}
+		}
+	}
+
+	if _, err := NewRijndael(key[:16], 20); !errors.Is(err, ErrBlockSize) {
+		t.Errorf("[TestRijndael] failed: err != expected : '%v' != '%v'", err, ErrBlockSize)
+	}
+}
+
+func TestRijndaelMode(t *testing.T) {
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	plainText := make([]byte, 100)
+	for i := range plainText {
+		plainText[i] = byte(i)
+	}
+
+	for _, blockSize := range []int{24, 32} {
+		b, err := NewRijndael(key, blockSize)
+		if err != nil {
+			t.Fatalf("[TestRijndaelMode] block size %d failed: %v", blockSize, err)
+		}
+		iv := make([]byte, blockSize)
+		for i := range iv {
+			iv[i] = byte(0xf0 + i)
+		}
+
+		modes := []struct {
+			name    string
+			encrypt func(in []byte) ([]byte, error)
+			decrypt func(in []byte) ([]byte, error)
+		}{
+			{"ECB", func(in []byte) ([]byte, error) { return ECBCipher(b, in, nil) }, func(in []byte) ([]byte, error) { return ECBInvCipher(b, in, nil) }},
+			{"CBC", func(in []byte) ([]byte, error) { return CBCCipher(b, in, iv, nil) }, func(in []byte) ([]byte, error) { return CBCInvCipher(b, in, iv, nil) }},
+			{"CBCCTS", func(in []byte) ([]byte, error) { return CBCCTSCipher(b, in, iv) }, func(in []byte) ([]byte, error) { return CBCCTSInvCipher(b, in, iv) }},
+			{"CFB", func(in []byte) ([]byte, error) { return CFBCipher(b, in, iv) }, func(in []byte) ([]byte, error) { return CFBInvCipher(b, in, iv) }},
+			{"CFB8", func(in []byte) ([]byte, error) { return CFBSegmentCipher(b, in, iv, 8) }, func(in []byte) ([]byte, error) { return CFBSegmentInvCipher(b, in, iv, 8) }},
+			{"OFB", func(in []byte) ([]byte, error) { return OFBCipher(b, in, iv) }, func(in []byte) ([]byte, error) { return OFBInvCipher(b, in, iv) }},
+			{"CTR", func(in []byte) ([]byte, error) { return CTRCipher(b, in, iv) }, func(in []byte) ([]byte, error) { return CTRInvCipher(b, in, iv) }},
+		}
+		for _, mode := range modes {
+			cipherText, err := mode.encrypt(plainText)
+			if err != nil {
+				t.Fatalf("[TestRijndaelMode] %s with block size %d failed: %v", mode.name, blockSize, err)
+			}
+			decrypted, err := mode.decrypt(cipherText)
+			if err != nil {
+				t.Fatalf("[TestRijndaelMode] %s with block size %d failed: %v", mode.name, blockSize, err)
+			}
+			if !bytes.Equal(decrypted, plainText) {
+				t.Errorf("[TestRijndaelMode] %s with block size %d failed: decrypted != plainText :\ndecrypted:\t%s\nexpected:\t%s", mode.name, blockSize, PrintableBytes(decrypted), PrintableBytes(plainText))
+			}
+		}
+
+		// modes defined only for 128 bit block
+		if _, err := NewGCM(b); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[TestRijndaelMode] GCM with block size %d failed: err != expected : '%v' != '%v'", blockSize, err, ErrBlockSize)
+		}
+		if _, err := CCMCipher(b, plainText, iv[:12]); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[TestRijndaelMode] CCM with block size %d failed: err != expected : '%v' != '%v'", blockSize, err, ErrBlockSize)
+		}
+		if _, err := NewCMAC(b, 16); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[TestRijndaelMode] CMAC with block size %d failed: err != expected : '%v' != '%v'", blockSize, err, ErrBlockSize)
+		}
+		if _, err := NewOCB(b, 16); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[TestRijndaelMode] OCB with block size %d failed: err != expected : '%v' != '%v'", blockSize, err, ErrBlockSize)
+		}
+		if _, err := Wrap(b, plainText[:16]); !errors.Is(err, ErrBlockSize) {
+			t.Errorf("[TestRijndaelMode] key wrap with block size %d failed: err != expected : '%v' != '%v'", blockSize, err, ErrBlockSize)
+		}
+	}
+}
+
+func TestTracer(t *testing.T) {
+	// test vectors are defined in FIPS-197 Appendix C.1
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	plainText := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	cipherText := []byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a}
+	// round[1].start and round[1].s_box
+	round1Start := []byte{0x00, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0, 0xd0, 0xe0, 0xf0}
+	round1SubBytes := []byte{0x63, 0xca, 0xb7, 0x04, 0x09, 0x53, 0xd0, 0x51, 0xcd, 0x60, 0xe0, 0xe7, 0xba, 0x70, 0xe1, 0x8c}
+
+	b, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestTracer] failed: %v", err)
+	}
+	recorder := &TraceRecorder{}
+	b.SetTracer(recorder)
+
+	out := make([]byte, 16)
+	b.Encrypt(out, plainText)
+	events := recorder.Events()
+	// AddRoundKey, 9 rounds of 4 steps and the last round without MixColumns
+	if len(events) != 1+9*4+3 {
+		t.Fatalf("[TestTracer] failed: the number of events != expected : '%d' != '%d'", len(events), 1+9*4+3)
+	}
+	for i, event := range events {
+		if event.Decrypt {
+			t.Errorf("[TestTracer] event %d failed: encryption event is marked as decryption", i)
+		}
+		if i > 0 && !bytes.Equal(event.Before, events[i-1].After) {
+			t.Errorf("[TestTracer] event %d failed: before != after of previous event", i)
+		}
+		if (event.Step == StepAddRoundKey) != (event.RoundKey != nil) {
+			t.Errorf("[TestTracer] event %d failed: round key of %s is %v", i, event.Step, event.RoundKey)
+		}
+	}
+	if !bytes.Equal(events[0].Before, plainText) || !bytes.Equal(events[len(events)-1].After, cipherText) {
+		t.Errorf("[TestTracer] failed: events don't start with plain text and end with cipher text")
+	}
+	if events[1].Round != 1 || events[1].Step != StepSubBytes || !bytes.Equal(events[1].Before, round1Start) || !bytes.Equal(events[1].After, round1SubBytes) {
+		t.Errorf("[TestTracer] failed: unexpected SubBytes event of round 1 : %+v", events[1])
+	}
+	round1 := events[4].After
+
+	recorder.Reset()
+	b.Decrypt(out, cipherText)
+	events = recorder.Events()
+	if len(events) != 1+9*4+3 {
+		t.Fatalf("[TestTracer] failed: the number of events != expected : '%d' != '%d'", len(events), 1+9*4+3)
+	}
+	if !events[0].Decrypt || events[0].Round != 10 || !bytes.Equal(events[len(events)-1].After, plainText) {
+		t.Errorf("[TestTracer] failed: unexpected decryption events : first %+v, last %+v", events[0], events[len(events)-1])
+	}
+
+	// T-table implementation traces a whole round as a step
+	tb, err := NewCipherWithImplementation(key, ImplementationTTable)
+	if err != nil {
+		t.Fatalf("[TestTracer] failed: %v", err)
+	}
+	recorder.Reset()
+	tb.SetTracer(recorder)
+	tb.Encrypt(out, plainText)
+	events = recorder.Events()
+	if len(events) != 11 {
+		t.Fatalf("[TestTracer] failed: the number of events != expected : '%d' != '%d'", len(events), 11)
+	}
+	if events[1].Step != StepRound || !bytes.Equal(events[1].After, round1) || !bytes.Equal(events[10].After, cipherText) {
+		t.Errorf("[TestTracer] failed: unexpected T-table events : round 1 %+v, round 10 %+v", events[1], events[10])
+	}
+
+	var buf bytes.Buffer
+	jsonTracer := NewJSONTracer(&buf)
+	jb, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestTracer] failed: %v", err)
+	}
+	jb.SetTracer(NewRoundFilter(jsonTracer, 1))
+	jb.Encrypt(out, plainText)
+	if err := jsonTracer.Err(); err != nil {
+		t.Errorf("[TestTracer] failed: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 4 {
+		t.Fatalf("[TestTracer] failed: the number of JSON lines != expected : '%d' != '%d'", len(lines), 4)
+	}
+	expected := `{"round":1,"step":"SubBytes","decrypt":false,"before":"00102030405060708090a0b0c0d0e0f0","after":"63cab7040953d051cd60e0e7ba70e18c"}`
+	if string(lines[0]) != expected {
+		t.Errorf("[TestTracer] failed: JSON != expected :\njson:\t\t%s\nexpected:\t%s", lines[0], expected)
+	}
+
+	buf.Reset()
+	textTracer := NewTextTracer(&buf)
+	xb, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestTracer] failed: %v", err)
+	}
+	xb.SetTracer(NewRoundFilter(textTracer, 1))
+	xb.Encrypt(out, plainText)
+	if err := textTracer.Err(); err != nil {
+		t.Errorf("[TestTracer] failed: %v", err)
+	}
+	expected = "[Round 1] SubBytes\n  Before: 0x00 0x10 0x20 0x30 0x40 0x50 0x60 0x70 0x80 0x90 0xa0 0xb0 0xc0 0xd0 0xe0 0xf0\n  After:  0x63 0xca 0xb7 0x04 0x09 0x53 0xd0 0x51 0xcd 0x60 0xe0 0xe7 0xba 0x70 0xe1 0x8c\n"
+	if !bytes.HasPrefix(buf.Bytes(), []byte(expected)) {
+		t.Errorf("[TestTracer] failed: text != expected :\ntext:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+// errWriter is io.Writer which always fails
+type errWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestTracerWriteError(t *testing.T) {
+	key := make([]byte, 16)
+	tracers := []interface {
+		Tracer
+		Err() error
+	}{NewJSONTracer(errWriter{}), NewTextTracer(errWriter{})}
+
+	for i, tracer := range tracers {
+		b, err := NewCipher(key)
+		if err != nil {
+			t.Fatalf("[TestTracerWriteError] case %d failed: %v", i, err)
+		}
+		b.SetTracer(tracer)
+		out := make([]byte, 16)
+		b.Encrypt(out, out)
+		if err := tracer.Err(); !errors.Is(err, errWrite) {
+			t.Errorf("[TestTracerWriteError] case %d failed: err != expected : '%v' != '%v'", i, err, errWrite)
+		}
+	}
+}
+
+func TestEqInvCipher(t *testing.T) {
+	key := []byte{
+		0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c,
+		0x76, 0x2e, 0x71, 0x60, 0xf3, 0x8b, 0x4d, 0xa5, 0x6a, 0x78, 0x4d, 0x90, 0x45, 0x19, 0x0c, 0xfe,
+	}
+	plainText := []byte{
+		0x32, 0x43, 0xf6, 0xa8, 0x88, 0x5a, 0x30, 0x8d, 0x31, 0x31, 0x98, 0xa2, 0xe0, 0x37, 0x07, 0x34,
+		0x4a, 0x40, 0x93, 0x82, 0x22, 0x99, 0xf3, 0x1d, 0x00, 0x82, 0xef, 0xa9, 0x8e, 0xc4, 0xe6, 0xc8,
+	}
+
+	// every combination of Rijndael key size and block size
+	for _, blockSize := range []int{16, 24, 32} {
+		for _, keySize := range []int{16, 24, 32} {
+			b, err := NewRijndael(key[:keySize], blockSize)
+			if err != nil {
+				t.Fatalf("[TestEqInvCipher] case %d-%d failed: %v", blockSize, keySize, err)
+			}
+			cipherText := make([]byte, blockSize)
+			b.Encrypt(cipherText, plainText)
+			decrypted := make([]byte, blockSize)
+			b.EqInvCipher(decrypted, cipherText)
+			if !bytes.Equal(decrypted, plainText[:blockSize]) {
+				t.Errorf("[TestEqInvCipher] case %d-%d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", blockSize, keySize, PrintableBytes(decrypted), PrintableBytes(plainText[:blockSize]))
+			}
+		}
+	}
+
+	// steps are in the same order as the cipher
+	b, err := NewCipher(key[:16])
+	if err != nil {
+		t.Fatalf("[TestEqInvCipher] failed: %v", err)
+	}
+	recorder := &TraceRecorder{}
+	b.SetTracer(recorder)
+	out := make([]byte, 16)
+	b.EqInvCipher(out, plainText)
+	expected := []string{StepInvSubBytes, StepInvShiftRows, StepInvMixColumns, StepAddRoundKey}
+	events := recorder.Events()
+	for i, step := range expected {
+		if events[1+i].Step != step {
+			t.Errorf("[TestEqInvCipher] step %d failed: step != expected : '%s' != '%s'", i, events[1+i].Step, step)
+		}
+	}
+	// round keys of the middle rounds are transformed with InvMixColumns
+	roundKey := make([]byte, 16)
+	copy(roundKey, b.roundKey(9))
+	InvMixColumns(roundKey)
+	if !bytes.Equal(events[4].RoundKey, roundKey) {
+		t.Errorf("[TestEqInvCipher] failed: round key != expected :\nkey:\t\t%s\nexpected:\t%s", PrintableBytes(events[4].RoundKey), PrintableBytes(roundKey))
+	}
+}
+
+func TestCipherWithRounds(t *testing.T) {
+	// test vectors are defined in FIPS-197 Appendix C.1
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	plainText := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	expected := []byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a}
+
+	b, err := NewCipherWithRounds(key, NumOfRounds128)
+	if err != nil {
+		t.Fatalf("[TestCipherWithRounds] failed: %v", err)
+	}
+	cipherText := make([]byte, 16)
+	b.Encrypt(cipherText, plainText)
+	if !bytes.Equal(cipherText, expected) {
+		t.Errorf("[TestCipherWithRounds] failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", PrintableBytes(cipherText), PrintableBytes(expected))
+	}
+
+	for rounds := 1; rounds <= 4; rounds++ {
+		b, err := NewCipherWithRounds(key, rounds)
+		if err != nil {
+			t.Fatalf("[TestCipherWithRounds] %d rounds failed: %v", rounds, err)
+		}
+		if b.Rounds() != rounds {
+			t.Errorf("[TestCipherWithRounds] %d rounds failed: rounds != expected : '%d' != '%d'", rounds, b.Rounds(), rounds)
+		}
+		recorder := &TraceRecorder{}
+		b.SetTracer(recorder)
+		b.Encrypt(cipherText, plainText)
+		// the last round doesn't have MixColumns
+		if n := len(recorder.Events()); n != 1+(rounds-1)*4+3 {
+			t.Errorf("[TestCipherWithRounds] %d rounds failed: the number of events != expected : '%d' != '%d'", rounds, n, 1+(rounds-1)*4+3)
+		}
+		decrypted := make([]byte, 16)
+		b.Decrypt(decrypted, cipherText)
+		if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestCipherWithRounds] %d rounds failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", rounds, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+	}
+
+	if _, err := NewCipherWithRounds(key, 0); !errors.Is(err, ErrRounds) {
+		t.Errorf("[TestCipherWithRounds] failed: err != expected : '%v' != '%v'", err, ErrRounds)
+	}
 }
@@ -0,0 +1,137 @@
+package aes
+
+// Bitsliced S-box for the constant-time implementation.
+// The state is transposed into 8 bit planes; planes[i] holds bit i of every byte
+// and byte j of the state is placed at bit j of each plane.
+// S-box is computed as GF(2^8) inversion followed by the affine transformation
+// using only AND and XOR on the planes, so every byte is processed by the same circuit.
+
+// sboxConstant is the constant of the affine transformation of S-box
+const sboxConstant = 0x63
+
+// invSboxConstant is the constant of the inverse affine transformation of S-box
+const invSboxConstant = 0x05
+
+// bitPlanes is the bitsliced form of up to 32 bytes
+type bitPlanes [8]uint32
+
+// subBytes transforms given state with S-box of the implementation of b
+func (b *Block) subBytes(state []byte) {
+	switch {
+	case b.variant != nil:
+		substitute(state, &b.variant.s)
+	case b.impl == ImplementationBitsliced:
+		bitslicedSubBytes(state)
+	default:
+		SubBytes(state)
+	}
+}
+
+// invSubBytes transforms given state with inverse S-box of the implementation of b
+func (b *Block) invSubBytes(state []byte) {
+	switch {
+	case b.variant != nil:
+		substitute(state, &b.variant.inv)
+	case b.impl == ImplementationBitsliced:
+		bitslicedInvSubBytes(state)
+	default:
+		InvSubBytes(state)
+	}
+}
+
+// bitslicedSubBytes transforms given state with bitsliced S-box
+func bitslicedSubBytes(state []byte) {
+	p := toBitPlanes(state)
+	p = gfInvPlanes(p)
+	p = affinePlanes(p, len(state))
+	fromBitPlanes(p, state)
+}
+
+// bitslicedInvSubBytes transforms given state with bitsliced inverse S-box
+func bitslicedInvSubBytes(state []byte) {
+	p := toBitPlanes(state)
+	p = invAffinePlanes(p, len(state))
+	p = gfInvPlanes(p)
+	fromBitPlanes(p, state)
+}
+
+// toBitPlanes transposes given bytes into bit planes
+func toBitPlanes(in []byte) (p bitPlanes) {
+	for j, x := range in {
+		for i := 0; i < 8; i++ {
+			p[i] |= uint32(x>>uint(i)&1) << uint(j)
+		}
+	}
+	return
+}
+
+// fromBitPlanes transposes bit planes back into bytes
+func fromBitPlanes(p bitPlanes, out []byte) {
+	for j := range out {
+		var x byte
+		for i := 0; i < 8; i++ {
+			x |= byte(p[i]>>uint(j)&1) << uint(i)
+		}
+		out[j] = x
+	}
+}
+
+// gfMulPlanes multiplies a and b in GF(2^8) for every byte position
+func gfMulPlanes(a, b bitPlanes) bitPlanes {
+	var t [15]uint32
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			t[i+j] ^= a[i] & b[j]
+		}
+	}
+	// reduce with x⁸ = x⁴ + x³ + x + 1 from the highest degree
+	for k := 14; k >= 8; k-- {
+		t[k-4] ^= t[k]
+		t[k-5] ^= t[k]
+		t[k-7] ^= t[k]
+		t[k-8] ^= t[k]
+	}
+
+	var p bitPlanes
+	copy(p[:], t[:8])
+	return p
+}
+
+// gfInvPlanes calculates the multiplicative inverse in GF(2^8) for every byte position as x²⁵⁴.
+// 0 is mapped to 0 as defined in FIPS-197.
+func gfInvPlanes(x bitPlanes) bitPlanes {
+	x2 := gfMulPlanes(x, x)
+	x3 := gfMulPlanes(x2, x)
+	x6 := gfMulPlanes(x3, x3)
+	x12 := gfMulPlanes(x6, x6)
+	x14 := gfMulPlanes(x12, x2)
+	x15 := gfMulPlanes(x12, x3)
+	x30 := gfMulPlanes(x15, x15)
+	x60 := gfMulPlanes(x30, x30)
+	x120 := gfMulPlanes(x60, x60)
+	x240 := gfMulPlanes(x120, x120)
+	return gfMulPlanes(x240, x14)
+}
+
+// affinePlanes applies the affine transformation of S-box to bit planes of n bytes
+func affinePlanes(x bitPlanes, n int) bitPlanes {
+	mask := ^uint32(0) >> uint(32-n)
+	var p bitPlanes
+	for i := 0; i < 8; i++ {
+		p[i] = x[i] ^ x[(i+4)%8] ^ x[(i+5)%8] ^ x[(i+6)%8] ^ x[(i+7)%8]
+		// -(c & 1) is all ones if i-th bit of the constant is set
+		p[i] ^= mask & -uint32(sboxConstant>>uint(i)&1)
+	}
+	return p
+}
+
+// invAffinePlanes applies the inverse affine transformation of S-box to bit planes of n bytes
+func invAffinePlanes(x bitPlanes, n int) bitPlanes {
+	mask := ^uint32(0) >> uint(32-n)
+	var p bitPlanes
+	for i := 0; i < 8; i++ {
+		p[i] = x[(i+2)%8] ^ x[(i+5)%8] ^ x[(i+7)%8]
+		p[i] ^= mask & -uint32(invSboxConstant>>uint(i)&1)
+	}
+	return p
+}
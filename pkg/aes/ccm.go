@@ -0,0 +1,239 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// ccmBlockSize is the block size (byte) of CCM. It's fixed to 128 bit.
+	ccmBlockSize = 16
+	// ccmMinimumNonceSize is the minimum nonce size (byte) of CCM
+	ccmMinimumNonceSize = 7
+	// ccmMaximumNonceSize is the maximum nonce size (byte) of CCM
+	ccmMaximumNonceSize = 13
+	// ccmMinimumTagSize is the minimum tag size (byte) of CCM
+	ccmMinimumTagSize = 4
+	// ccmMaximumTagSize is the maximum tag size (byte) of CCM
+	ccmMaximumTagSize = 16
+)
+
+// ErrNonceSize is returned when given nonce size is not supported by the mode
+var ErrNonceSize = errors.New("invalid nonce size for the encryption mode")
+
+// CCM is an AES-CCM instance defined in NIST SP 800-38C and RFC 3610.
+// It implements crypto/cipher.AEAD.
+type CCM struct {
+	b         *Block
+	nonceSize int
+	tagSize   int
+}
+
+var _ cipher.AEAD = (*CCM)(nil)
+
+// NewCCM returns CCM with given nonce size and tag size.
+// Nonce size must be between 7 and 13 bytes, and tag size must be an even number between 4 and 16 bytes.
+// Nonce size n limits the length of plain text to 2^(8*(15-n)) bytes.
+func NewCCM(b *Block, nonceSize, tagSize int) (*CCM, error) {
+	if b.BlockSize() != ccmBlockSize {
+		return nil, ErrBlockSize
+	}
+	if nonceSize < ccmMinimumNonceSize || nonceSize > ccmMaximumNonceSize {
+		return nil, ErrNonceSize
+	}
+	if tagSize < ccmMinimumTagSize || tagSize > ccmMaximumTagSize || tagSize%2 != 0 {
+		return nil, ErrTagSize
+	}
+	return &CCM{
+		b:         b,
+		nonceSize: nonceSize,
+		tagSize:   tagSize,
+	}, nil
+}
+
+// NonceSize returns the nonce size
+func (c *CCM) NonceSize() int {
+	return c.nonceSize
+}
+
+// Overhead returns the tag size
+func (c *CCM) Overhead() int {
+	return c.tagSize
+}
+
+// Seal encrypts and authenticates plaintext, authenticates additionalData
+// and appends the result (cipher text followed by tag) to dst
+func (c *CCM) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("aes: incorrect nonce length given to CCM")
+	}
+	if !c.fitsLength(len(plaintext)) {
+		panic("aes: plaintext too large for CCM")
+	}
+
+	tag := c.cbcMAC(nonce, plaintext, additionalData)
+	s0 := c.counterBlock(nonce, 0)
+	c.b.blockCipher(s0)
+	for i := range tag {
+		tag[i] ^= s0[i]
+	}
+
+	dst = append(dst, c.ctr(nonce, plaintext)...)
+	return append(dst, tag...)
+}
+
+// Open decrypts and verifies ciphertext (cipher text followed by tag) with additionalData
+// and appends the plain text to dst. Plain text is not released if authentication fails.
+func (c *CCM) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("aes: incorrect nonce length given to CCM")
+	}
+	if len(ciphertext) < c.tagSize || !c.fitsLength(len(ciphertext)-c.tagSize) {
+		return nil, ErrAuthentication
+	}
+
+	tag := ciphertext[len(ciphertext)-c.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-c.tagSize]
+
+	plaintext := c.ctr(nonce, ciphertext)
+	expectedTag := c.cbcMAC(nonce, plaintext, additionalData)
+	s0 := c.counterBlock(nonce, 0)
+	c.b.blockCipher(s0)
+	for i := range expectedTag {
+		expectedTag[i] ^= s0[i]
+	}
+
+	if subtle.ConstantTimeCompare(tag, expectedTag) != 1 {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+		return nil, ErrAuthentication
+	}
+	return append(dst, plaintext...), nil
+}
+
+// CCMCipher encrypts given plain text with CCM mode without additional data.
+// The length of iv is used as nonce size, and returned cipher text is followed by 128 bit tag.
+func CCMCipher(b *Block, in, iv []byte) ([]byte, error) {
+	if b.BlockSize() != ccmBlockSize {
+		return nil, ErrBlockSize
+	}
+	c, err := NewCCM(b, len(iv), ccmMaximumTagSize)
+	if err != nil {
+		return nil, ErrIVSize
+	}
+	if !c.fitsLength(len(in)) {
+		return nil, ErrPlaintextLength
+	}
+	return c.Seal(nil, iv, in, nil), nil
+}
+
+// CCMInvCipher decrypts and verifies given cipher text followed by 128 bit tag with CCM mode
+func CCMInvCipher(b *Block, in, iv []byte) ([]byte, error) {
+	if b.BlockSize() != ccmBlockSize {
+		return nil, ErrBlockSize
+	}
+	c, err := NewCCM(b, len(iv), ccmMaximumTagSize)
+	if err != nil {
+		return nil, ErrIVSize
+	}
+	return c.Open(nil, iv, in, nil)
+}
+
+// fitsLength reports whether n bytes can be encoded in the length field of B0
+func (c *CCM) fitsLength(n int) bool {
+	l := ccmBlockSize - 1 - c.nonceSize
+	return l >= 8 || uint64(n) < uint64(1)<<uint(8*l)
+}
+
+// cbcMAC calculates CBC-MAC of formatted B0, additional data and plain text
+func (c *CCM) cbcMAC(nonce, plaintext, additionalData []byte) []byte {
+	l := ccmBlockSize - 1 - c.nonceSize
+
+	// B0 = Flags || N || Q
+	b0 := make([]byte, ccmBlockSize)
+	b0[0] = byte(((c.tagSize-2)/2)<<3 | (l - 1))
+	if len(additionalData) > 0 {
+		b0[0] |= 1 << 6
+	}
+	copy(b0[1:], nonce)
+	q := make([]byte, 8)
+	binary.BigEndian.PutUint64(q, uint64(len(plaintext)))
+	copy(b0[1+c.nonceSize:], q[8-l:])
+
+	x := make([]byte, ccmBlockSize)
+	c.cbcMACUpdate(x, b0)
+
+	if len(additionalData) > 0 {
+		var encoded []byte
+		switch {
+		case uint64(len(additionalData)) < 1<<16-1<<8:
+			encoded = make([]byte, 2)
+			binary.BigEndian.PutUint16(encoded, uint16(len(additionalData)))
+		case uint64(len(additionalData)) < 1<<32:
+			encoded = make([]byte, 6)
+			encoded[0], encoded[1] = 0xff, 0xfe
+			binary.BigEndian.PutUint32(encoded[2:], uint32(len(additionalData)))
+		default:
+			encoded = make([]byte, 10)
+			encoded[0], encoded[1] = 0xff, 0xff
+			binary.BigEndian.PutUint64(encoded[2:], uint64(len(additionalData)))
+		}
+		c.cbcMACUpdate(x, append(encoded, additionalData...))
+	}
+	c.cbcMACUpdate(x, plaintext)
+
+	return x[:c.tagSize]
+}
+
+// cbcMACUpdate absorbs given input padded with zero into x
+func (c *CCM) cbcMACUpdate(x, in []byte) {
+	for from := 0; from < len(in); from += ccmBlockSize {
+		to := from + ccmBlockSize
+		if to > len(in) {
+			to = len(in)
+		}
+		// XOR with previous cipher block
+		for j := from; j < to; j++ {
+			x[j-from] ^= in[j]
+		}
+		c.b.blockCipher(x)
+	}
+}
+
+// ctr encrypts given input with CTR mode starting from counter block A1
+func (c *CCM) ctr(nonce, in []byte) []byte {
+	numOfBlocks := c.b.numOfBlocks(len(in))
+	out := make([]byte, len(in))
+
+	for i := 0; i < numOfBlocks; i++ {
+		from := i * ccmBlockSize
+		to := (i + 1) * ccmBlockSize
+		if to > len(in) {
+			to = len(in)
+		}
+
+		state := c.counterBlock(nonce, uint64(i+1))
+		c.b.blockCipher(state)
+		// XOR with encrypted counter block
+		for j := from; j < to; j++ {
+			out[j] = in[j] ^ state[j-from]
+		}
+	}
+	return out
+}
+
+// counterBlock returns counter block Ai = Flags || N || [i]
+func (c *CCM) counterBlock(nonce []byte, i uint64) []byte {
+	l := ccmBlockSize - 1 - c.nonceSize
+
+	a := make([]byte, ccmBlockSize)
+	a[0] = byte(l - 1)
+	copy(a[1:], nonce)
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, i)
+	copy(a[1+c.nonceSize:], counter[8-l:])
+	return a
+}
@@ -0,0 +1,155 @@
+package aes
+
+import (
+	"hash"
+)
+
+const (
+	// cmacBlockSize is the block size (byte) of CMAC. It's fixed to 128 bit.
+	cmacBlockSize = 16
+	// cmacTagSize is the default (and maximum) tag size (byte) of CMAC
+	cmacTagSize = 16
+)
+
+// CMAC is an AES-CMAC instance defined in NIST SP 800-38B and RFC 4493.
+// It implements hash.Hash. Sum returns the tag truncated to the tag size.
+type CMAC struct {
+	b       *Block
+	tagSize int
+	k1      []byte // K1 = dbl(L) where L = CIPH_K(0^128)
+	k2      []byte // K2 = dbl(K1)
+	x       []byte // chaining value of processed blocks
+	buf     []byte // unprocessed input. The last block is kept until Sum is called.
+}
+
+var _ hash.Hash = (*CMAC)(nil)
+
+// NewCMAC returns CMAC with given tag size.
+// Tag size must be between 1 and 16 bytes.
+func NewCMAC(b *Block, tagSize int) (*CMAC, error) {
+	if b.BlockSize() != cmacBlockSize {
+		return nil, ErrBlockSize
+	}
+	if tagSize < 1 || tagSize > cmacTagSize {
+		return nil, ErrTagSize
+	}
+
+	l := make([]byte, cmacBlockSize)
+	b.blockCipher(l)
+	k1 := Xtime128(l)
+	k2 := Xtime128(k1)
+	return &CMAC{
+		b:       b,
+		tagSize: tagSize,
+		k1:      k1,
+		k2:      k2,
+		x:       make([]byte, cmacBlockSize),
+		buf:     make([]byte, 0, cmacBlockSize),
+	}, nil
+}
+
+// Size returns the tag size
+func (c *CMAC) Size() int {
+	return c.tagSize
+}
+
+// BlockSize returns the block size
+func (c *CMAC) BlockSize() int {
+	return cmacBlockSize
+}
+
+// Reset resets CMAC to its initial state
+func (c *CMAC) Reset() {
+	for j := range c.x {
+		c.x[j] = 0
+	}
+	c.buf = c.buf[:0]
+}
+
+// Write adds given data to the running CMAC. It never returns an error.
+func (c *CMAC) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(c.buf) == cmacBlockSize {
+			// buffered block is not the last one
+			for j := 0; j < cmacBlockSize; j++ {
+				c.x[j] ^= c.buf[j]
+			}
+			c.b.blockCipher(c.x)
+			c.buf = c.buf[:0]
+		}
+		l := cmacBlockSize - len(c.buf)
+		if l > len(p) {
+			l = len(p)
+		}
+		c.buf = append(c.buf, p[:l]...)
+		p = p[l:]
+	}
+	return n, nil
+}
+
+// Sum appends the current tag to b. It does not change the underlying state.
+func (c *CMAC) Sum(b []byte) []byte {
+	x := make([]byte, cmacBlockSize)
+	copy(x, c.x)
+	c.last(x, c.buf)
+	return append(b, x[:c.tagSize]...)
+}
+
+// checksum calculates full-length CMAC of given message without changing the underlying state
+func (c *CMAC) checksum(msg []byte) []byte {
+	x := make([]byte, cmacBlockSize)
+	for len(msg) > cmacBlockSize {
+		for j := 0; j < cmacBlockSize; j++ {
+			x[j] ^= msg[j]
+		}
+		c.b.blockCipher(x)
+		msg = msg[cmacBlockSize:]
+	}
+	c.last(x, msg)
+	return x
+}
+
+// last processes the last (possibly partial or empty) block into chaining value x
+func (c *CMAC) last(x, block []byte) {
+	for j := range block {
+		x[j] ^= block[j]
+	}
+	if len(block) == cmacBlockSize {
+		for j := 0; j < cmacBlockSize; j++ {
+			x[j] ^= c.k1[j]
+		}
+	} else {
+		x[len(block)] ^= 0x80
+		for j := 0; j < cmacBlockSize; j++ {
+			x[j] ^= c.k2[j]
+		}
+	}
+	c.b.blockCipher(x)
+}
+
+// CMACPRF128 calculates AES-CMAC-PRF-128 defined in RFC 4615.
+// The key may have any length; keys other than 128 bit are derived with AES-CMAC under zero key.
+func CMACPRF128(key, msg []byte) ([]byte, error) {
+	if len(key) != 16 {
+		zero, err := NewCipher(make([]byte, 16))
+		if err != nil {
+			return nil, err
+		}
+		c, err := NewCMAC(zero, cmacTagSize)
+		if err != nil {
+			return nil, err
+		}
+		key = c.checksum(key)
+	}
+
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCMAC(b, cmacTagSize)
+	if err != nil {
+		return nil, err
+	}
+	return c.checksum(msg), nil
+}
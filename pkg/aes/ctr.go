@@ -0,0 +1,146 @@
+package aes
+
+import (
+	"errors"
+)
+
+// ctrDefaultCounterSize is the counter size (byte) used by CTRCipher
+const ctrDefaultCounterSize = 8
+
+var (
+	// ErrCounterSize is returned when given counter size doesn't fit in the counter block
+	ErrCounterSize = errors.New("invalid counter size for CTR mode")
+	// ErrCounterOverflow is returned when CTR counter would wrap around and repeat key stream
+	ErrCounterOverflow = errors.New("CTR counter overflow")
+)
+
+// CTRConfig represents the counter block layout of CTR mode.
+// The counter occupies the last CounterSize bytes of the counter block
+// and the rest of the initial counter block is used as nonce.
+type CTRConfig struct {
+	// CounterSize is the counter width (byte). 0 selects 8 (64 bit counter).
+	// Use 4 for GCM-style 32 bit counter and 16 for full 128 bit counter of SP 800-38A.
+	CounterSize int
+	// LittleEndian selects little-endian counter instead of big-endian
+	LittleEndian bool
+	// Offset is the number of blocks to skip from the initial counter block
+	Offset uint64
+}
+
+// CTRCipherWithConfig encrypts given plain text with CTR mode of given counter layout.
+// It returns ErrCounterOverflow instead of wrapping the counter.
+func CTRCipherWithConfig(b *Block, in, iv []byte, config CTRConfig) ([]byte, error) {
+	c, err := newCTRCounter(b, iv, config)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(in))
+	if err := ctrXORBlocks(b, c, out, in); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CTRInvCipherWithConfig decrypts given cipher text with CTR mode of given counter layout
+func CTRInvCipherWithConfig(b *Block, in, iv []byte, config CTRConfig) ([]byte, error) {
+	return CTRCipherWithConfig(b, in, iv, config)
+}
+
+// ctrCounter is a counter block of CTR mode
+type ctrCounter struct {
+	block        []byte
+	counterSize  int
+	littleEndian bool
+	// wrap makes the counter wrap around instead of returning ErrCounterOverflow.
+	// It's used by GCM whose inc32 is defined modulo 2^32.
+	wrap bool
+}
+
+// newCTRCounter returns counter block initialized with iv and seeked to config.Offset
+func newCTRCounter(b *Block, iv []byte, config CTRConfig) (*ctrCounter, error) {
+	blockSize := b.BlockSize()
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+
+	counterSize := config.CounterSize
+	if counterSize == 0 {
+		counterSize = ctrDefaultCounterSize
+	}
+	if counterSize < 1 || counterSize > blockSize {
+		return nil, ErrCounterSize
+	}
+
+	block := make([]byte, blockSize)
+	copy(block, iv)
+	c := &ctrCounter{
+		block:        block,
+		counterSize:  counterSize,
+		littleEndian: config.LittleEndian,
+	}
+	if err := c.add(config.Offset); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// inc increments the counter
+func (c *ctrCounter) inc() error {
+	return c.add(1)
+}
+
+// add adds n to the counter. It returns ErrCounterOverflow if the counter wraps around unless c.wrap is set.
+func (c *ctrCounter) add(n uint64) error {
+	field := c.block[len(c.block)-c.counterSize:]
+
+	// calculate on a copy not to change the counter on overflow
+	sum := make([]byte, len(field))
+	copy(sum, field)
+	var carry uint64
+	for k := 0; k < len(sum); k++ {
+		// index of k-th least significant byte
+		idx := len(sum) - 1 - k
+		if c.littleEndian {
+			idx = k
+		}
+		s := uint64(sum[idx]) + n&0xff + carry
+		sum[idx] = byte(s)
+		carry = s >> 8
+		n >>= 8
+	}
+	if (n != 0 || carry != 0) && !c.wrap {
+		return ErrCounterOverflow
+	}
+	copy(field, sum)
+	return nil
+}
+
+// ctrXORBlocks XORs src with key stream starting from the current counter block and stores the result in dst.
+// The counter is incremented between blocks.
+func ctrXORBlocks(b *Block, c *ctrCounter, dst, src []byte) error {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(src))
+
+	for i := 0; i < numOfBlocks; i++ {
+		if i > 0 {
+			if err := c.inc(); err != nil {
+				return err
+			}
+		}
+		from := i * blockSize
+		to := (i + 1) * blockSize
+		if to > len(src) {
+			to = len(src)
+		}
+
+		state := make([]byte, blockSize)
+		copy(state, c.block)
+		b.blockCipher(state)
+		// XOR with encrypted counter block
+		for j := from; j < to; j++ {
+			dst[j] = src[j] ^ state[j-from]
+		}
+	}
+	return nil
+}
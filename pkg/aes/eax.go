@@ -0,0 +1,135 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+const (
+	// eaxBlockSize is the block size (byte) of EAX. It's fixed to 128 bit.
+	eaxBlockSize = 16
+	// eaxStandardNonceSize is the recommended nonce size (byte) of EAX
+	eaxStandardNonceSize = 16
+	// eaxTagSize is the default (and maximum) tag size (byte) of EAX
+	eaxTagSize = 16
+)
+
+// EAX is an AES-EAX instance defined in "The EAX Mode of Operation" by Bellare, Rogaway and Wagner.
+// It implements crypto/cipher.AEAD.
+type EAX struct {
+	b       *Block
+	mac     *CMAC
+	tagSize int
+}
+
+var _ cipher.AEAD = (*EAX)(nil)
+
+// NewEAX returns EAX with given tag size.
+// Tag size must be between 1 and 16 bytes.
+func NewEAX(b *Block, tagSize int) (*EAX, error) {
+	if tagSize < 1 || tagSize > eaxTagSize {
+		return nil, ErrTagSize
+	}
+	mac, err := NewCMAC(b, cmacTagSize)
+	if err != nil {
+		return nil, err
+	}
+	return &EAX{
+		b:       b,
+		mac:     mac,
+		tagSize: tagSize,
+	}, nil
+}
+
+// NonceSize returns the recommended nonce size.
+// Seal and Open accept nonce of any length.
+func (e *EAX) NonceSize() int {
+	return eaxStandardNonceSize
+}
+
+// Overhead returns the tag size
+func (e *EAX) Overhead() int {
+	return e.tagSize
+}
+
+// Seal encrypts and authenticates plaintext, authenticates additionalData
+// and appends the result (cipher text followed by tag) to dst
+func (e *EAX) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	n := e.omac(0, nonce)
+	h := e.omac(1, additionalData)
+	out := e.ctr(n, plaintext)
+	c := e.omac(2, out)
+
+	// Tag = N xor C xor H
+	tag := make([]byte, eaxBlockSize)
+	for j := 0; j < eaxBlockSize; j++ {
+		tag[j] = n[j] ^ c[j] ^ h[j]
+	}
+
+	dst = append(dst, out...)
+	return append(dst, tag[:e.tagSize]...)
+}
+
+// Open decrypts and verifies ciphertext (cipher text followed by tag) with additionalData
+// and appends the plain text to dst. Plain text is not released if authentication fails.
+func (e *EAX) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < e.tagSize {
+		return nil, ErrAuthentication
+	}
+
+	tag := ciphertext[len(ciphertext)-e.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-e.tagSize]
+
+	n := e.omac(0, nonce)
+	h := e.omac(1, additionalData)
+	c := e.omac(2, ciphertext)
+	expectedTag := make([]byte, eaxBlockSize)
+	for j := 0; j < eaxBlockSize; j++ {
+		expectedTag[j] = n[j] ^ c[j] ^ h[j]
+	}
+	if subtle.ConstantTimeCompare(tag, expectedTag[:e.tagSize]) != 1 {
+		return nil, ErrAuthentication
+	}
+	return append(dst, e.ctr(n, ciphertext)...), nil
+}
+
+// omac calculates OMAC^t(M) = OMAC([t]_n || M)
+func (e *EAX) omac(t byte, msg []byte) []byte {
+	in := make([]byte, eaxBlockSize+len(msg))
+	in[eaxBlockSize-1] = t
+	copy(in[eaxBlockSize:], msg)
+	return e.mac.checksum(in)
+}
+
+// ctr encrypts given input with CTR mode using n as 128 bit big-endian counter
+func (e *EAX) ctr(n, in []byte) []byte {
+	counter := make([]byte, eaxBlockSize)
+	copy(counter, n)
+
+	numOfBlocks := e.b.numOfBlocks(len(in))
+	out := make([]byte, len(in))
+	for i := 0; i < numOfBlocks; i++ {
+		from := i * eaxBlockSize
+		to := (i + 1) * eaxBlockSize
+		if to > len(in) {
+			to = len(in)
+		}
+
+		state := make([]byte, eaxBlockSize)
+		copy(state, counter)
+		e.b.blockCipher(state)
+		// XOR with encrypted counter block
+		for j := from; j < to; j++ {
+			out[j] = in[j] ^ state[j-from]
+		}
+
+		// increment counter as 128 bit big-endian integer
+		for j := eaxBlockSize - 1; j >= 0; j-- {
+			counter[j]++
+			if counter[j] != 0 {
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// gcmBlockSize is the block size (byte) of GCM. It's fixed to 128 bit.
+	gcmBlockSize = 16
+	// gcmStandardNonceSize is the recommended nonce size (byte) of GCM
+	gcmStandardNonceSize = 12
+	// gcmTagSize is the default (and maximum) tag size (byte) of GCM
+	gcmTagSize = 16
+	// gcmMinimumTagSize is the minimum tag size (byte) supported by GCM
+	gcmMinimumTagSize = 12
+	// gcmCounterSize is the counter size (byte) of GCM. inc32 increments the rightmost 32 bit.
+	gcmCounterSize = 4
+	// gcmMaximumLength is the maximum length (byte) of plain text, 2^39-256 bit.
+	// The 32 bit counter doesn't repeat within a message of this length.
+	gcmMaximumLength = (1<<39 - 256) / 8
+)
+
+var (
+	// ErrTagSize is returned when given tag size is not supported by the mode
+	ErrTagSize = errors.New("invalid tag size for the encryption mode")
+	// ErrAuthentication is returned when cipher text or additional data fails authentication
+	ErrAuthentication = errors.New("message authentication failed")
+)
+
+// GCM is an AES-GCM instance defined in NIST SP 800-38D.
+// It implements crypto/cipher.AEAD.
+type GCM struct {
+	b       *Block
+	h       []byte // hash subkey H = CIPH_K(0^128)
+	tagSize int
+}
+
+var _ cipher.AEAD = (*GCM)(nil)
+
+// NewGCM returns GCM with 128 bit tag
+func NewGCM(b *Block) (*GCM, error) {
+	return NewGCMWithTagSize(b, gcmTagSize)
+}
+
+// NewGCMWithTagSize returns GCM with given tag size.
+// Tag size must be between 12 and 16 bytes (96 to 128 bit).
+func NewGCMWithTagSize(b *Block, tagSize int) (*GCM, error) {
+	if b.BlockSize() != gcmBlockSize {
+		return nil, ErrBlockSize
+	}
+	if tagSize < gcmMinimumTagSize || tagSize > gcmTagSize {
+		return nil, ErrTagSize
+	}
+
+	h := make([]byte, gcmBlockSize)
+	b.blockCipher(h)
+	return &GCM{
+		b:       b,
+		h:       h,
+		tagSize: tagSize,
+	}, nil
+}
+
+// NonceSize returns the recommended nonce size.
+// Seal and Open accept nonce of any non-zero length.
+func (g *GCM) NonceSize() int {
+	return gcmStandardNonceSize
+}
+
+// Overhead returns the tag size
+func (g *GCM) Overhead() int {
+	return g.tagSize
+}
+
+// Seal encrypts and authenticates plaintext, authenticates additionalData
+// and appends the result (cipher text followed by tag) to dst
+func (g *GCM) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) == 0 {
+		panic("aes: GCM nonce must not be empty")
+	}
+	if uint64(len(plaintext)) > gcmMaximumLength {
+		panic("aes: plaintext too large for GCM")
+	}
+
+	j0 := g.counter0(nonce)
+	// the plain text is encrypted from inc32(J0)
+	out := g.gctr(j0, 1, plaintext)
+	tag := g.tag(j0, additionalData, out)
+
+	dst = append(dst, out...)
+	return append(dst, tag...)
+}
+
+// Open decrypts and verifies ciphertext (cipher text followed by tag) with additionalData
+// and appends the plain text to dst. Plain text is not released if authentication fails.
+func (g *GCM) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) == 0 {
+		panic("aes: GCM nonce must not be empty")
+	}
+	if len(ciphertext) < g.tagSize || uint64(len(ciphertext)-g.tagSize) > gcmMaximumLength {
+		return nil, ErrAuthentication
+	}
+
+	tag := ciphertext[len(ciphertext)-g.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-g.tagSize]
+
+	j0 := g.counter0(nonce)
+	expectedTag := g.tag(j0, additionalData, ciphertext)
+	if subtle.ConstantTimeCompare(tag, expectedTag) != 1 {
+		return nil, ErrAuthentication
+	}
+
+	return append(dst, g.gctr(j0, 1, ciphertext)...), nil
+}
+
+// GCMCipher encrypts given plain text with GCM mode without additional data.
+// Returned cipher text is followed by 128 bit tag.
+func GCMCipher(b *Block, in, iv []byte) ([]byte, error) {
+	if len(iv) == 0 {
+		return nil, ErrIVSize
+	}
+	g, err := NewGCM(b)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(in)) > gcmMaximumLength {
+		return nil, ErrPlaintextLength
+	}
+	return g.Seal(nil, iv, in, nil), nil
+}
+
+// GCMInvCipher decrypts and verifies given cipher text followed by 128 bit tag with GCM mode
+func GCMInvCipher(b *Block, in, iv []byte) ([]byte, error) {
+	if len(iv) == 0 {
+		return nil, ErrIVSize
+	}
+	g, err := NewGCM(b)
+	if err != nil {
+		return nil, err
+	}
+	return g.Open(nil, iv, in, nil)
+}
+
+// counter0 returns pre-counter block J0 from given nonce
+func (g *GCM) counter0(nonce []byte) []byte {
+	j0 := make([]byte, gcmBlockSize)
+	if len(nonce) == gcmStandardNonceSize {
+		// J0 = IV || 0^31 || 1
+		copy(j0, nonce)
+		j0[gcmBlockSize-1] = 1
+		return j0
+	}
+	// J0 = GHASH(IV || 0^(s+64) || [len(IV)]64)
+	return g.ghash(nil, nonce)
+}
+
+// tag calculates authentication tag T = MSB_t(GCTR(J0, S))
+func (g *GCM) tag(j0, additionalData, ciphertext []byte) []byte {
+	s := g.ghash(additionalData, ciphertext)
+	return g.gctr(j0, 0, s)[:g.tagSize]
+}
+
+// gctr encrypts given input with CTR mode using 32 bit counter starting from offset blocks after given counter block.
+// The counter wraps around as inc32 of SP 800-38D; gcmMaximumLength keeps the key stream from repeating.
+func (g *GCM) gctr(cb []byte, offset uint64, in []byte) []byte {
+	c, err := newCTRCounter(g.b, cb, CTRConfig{CounterSize: gcmCounterSize})
+	if err != nil {
+		// cb is a block and the counter fits in it
+		panic(err)
+	}
+	c.wrap = true
+	c.add(offset)
+
+	out := make([]byte, len(in))
+	if err := ctrXORBlocks(g.b, c, out, in); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// ghash calculates GHASH of A || 0^v || C || 0^u || [len(A)]64 || [len(C)]64
+func (g *GCM) ghash(additionalData, ciphertext []byte) []byte {
+	y := make([]byte, gcmBlockSize)
+	g.ghashUpdate(y, additionalData)
+	g.ghashUpdate(y, ciphertext)
+
+	lengths := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+	g.ghashUpdate(y, lengths)
+	return y
+}
+
+// ghashUpdate absorbs given input padded with zero into y
+func (g *GCM) ghashUpdate(y, in []byte) {
+	for from := 0; from < len(in); from += gcmBlockSize {
+		to := from + gcmBlockSize
+		if to > len(in) {
+			to = len(in)
+		}
+		for j := from; j < to; j++ {
+			y[j-from] ^= in[j]
+		}
+		copy(y, gfMul128(y, g.h))
+	}
+}
+
+// gfMul128 multiplies x and y in GF(2^128) defined by x¹²⁸ + x⁷ + x² + x + 1.
+// Bits are ordered as in NIST SP 800-38D, so the first bit of x is the coefficient of x⁰.
+func gfMul128(x, y []byte) []byte {
+	z := make([]byte, gcmBlockSize)
+	v := make([]byte, gcmBlockSize)
+	copy(v, y)
+
+	for i := 0; i < 128; i++ {
+		if (x[i/8]>>uint(7-i%8))&1 == 1 {
+			for j := 0; j < gcmBlockSize; j++ {
+				z[j] ^= v[j]
+			}
+		}
+
+		// V = V >> 1 (xor R if LSB(V) is 1)
+		lsb := v[gcmBlockSize-1] & 1
+		for j := gcmBlockSize - 1; j > 0; j-- {
+			v[j] = v[j]>>1 | v[j-1]<<7
+		}
+		v[0] >>= 1
+		if lsb == 1 {
+			v[0] ^= 0xe1
+		}
+	}
+	return z
+}
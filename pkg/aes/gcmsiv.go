@@ -0,0 +1,205 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+const (
+	// gcmSIVBlockSize is the block size (byte) of GCM-SIV. It's fixed to 128 bit.
+	gcmSIVBlockSize = 16
+	// gcmSIVNonceSize is the nonce size (byte) of GCM-SIV
+	gcmSIVNonceSize = 12
+	// gcmSIVTagSize is the tag size (byte) of GCM-SIV
+	gcmSIVTagSize = 16
+	// gcmSIVMaximumLength is the maximum length (byte) of plain text and additional data
+	gcmSIVMaximumLength = 1 << 36
+)
+
+// GCMSIV is an AES-GCM-SIV instance defined in RFC 8452.
+// It implements crypto/cipher.AEAD.
+type GCMSIV struct {
+	b *Block // key-generating key
+}
+
+var _ cipher.AEAD = (*GCMSIV)(nil)
+
+// NewGCMSIV creates GCMSIV from given key.
+// The key must be 16 or 32 bytes to select AEAD_AES_128_GCM_SIV or AEAD_AES_256_GCM_SIV.
+func NewGCMSIV(key []byte) (*GCMSIV, error) {
+	if len(key) != 16 && len(key) != 32 {
+		return nil, ErrKeySize
+	}
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &GCMSIV{b: b}, nil
+}
+
+// NonceSize returns the nonce size
+func (g *GCMSIV) NonceSize() int {
+	return gcmSIVNonceSize
+}
+
+// Overhead returns the tag size
+func (g *GCMSIV) Overhead() int {
+	return gcmSIVTagSize
+}
+
+// Seal encrypts and authenticates plaintext, authenticates additionalData
+// and appends the result (cipher text followed by tag) to dst
+func (g *GCMSIV) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("aes: incorrect nonce length given to GCM-SIV")
+	}
+	if uint64(len(plaintext)) > gcmSIVMaximumLength || uint64(len(additionalData)) > gcmSIVMaximumLength {
+		panic("aes: message too large for GCM-SIV")
+	}
+
+	authKey, enc := g.deriveKeys(nonce)
+	tag := g.tag(authKey, enc, nonce, plaintext, additionalData)
+
+	dst = append(dst, g.ctr(enc, tag, plaintext)...)
+	return append(dst, tag...)
+}
+
+// Open decrypts and verifies ciphertext (cipher text followed by tag) with additionalData
+// and appends the plain text to dst. Plain text is not released if authentication fails.
+func (g *GCMSIV) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("aes: incorrect nonce length given to GCM-SIV")
+	}
+	if len(ciphertext) < gcmSIVTagSize ||
+		uint64(len(ciphertext)-gcmSIVTagSize) > gcmSIVMaximumLength || uint64(len(additionalData)) > gcmSIVMaximumLength {
+		return nil, ErrAuthentication
+	}
+
+	tag := ciphertext[len(ciphertext)-gcmSIVTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-gcmSIVTagSize]
+
+	authKey, enc := g.deriveKeys(nonce)
+	plaintext := g.ctr(enc, tag, ciphertext)
+	expectedTag := g.tag(authKey, enc, nonce, plaintext, additionalData)
+	if subtle.ConstantTimeCompare(tag, expectedTag) != 1 {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+		return nil, ErrAuthentication
+	}
+	return append(dst, plaintext...), nil
+}
+
+// deriveKeys derives message-authentication key and message-encryption key from nonce
+func (g *GCMSIV) deriveKeys(nonce []byte) ([]byte, *Block) {
+	// each derived block contributes its first 8 bytes
+	// to 128 bit authentication key and encryption key of the same length as the key
+	derived := make([]byte, 0, 16+g.b.nk*BytesOfWords)
+	for i := uint32(0); len(derived) < cap(derived); i++ {
+		state := make([]byte, gcmSIVBlockSize)
+		binary.LittleEndian.PutUint32(state, i)
+		copy(state[4:], nonce)
+		g.b.blockCipher(state)
+		derived = append(derived, state[:8]...)
+	}
+
+	authKey := derived[:16]
+	enc, err := NewCipher(derived[16:])
+	if err != nil {
+		// derived key has the same length as the key-generating key
+		panic(err)
+	}
+	return authKey, enc
+}
+
+// tag calculates tag = AES(encKey, POLYVAL(authKey, ...) xor nonce with MSB cleared)
+func (g *GCMSIV) tag(authKey []byte, enc *Block, nonce, plaintext, additionalData []byte) []byte {
+	s := polyval(authKey, additionalData, plaintext)
+	for i := 0; i < gcmSIVNonceSize; i++ {
+		s[i] ^= nonce[i]
+	}
+	s[gcmSIVBlockSize-1] &= 0x7f
+	enc.blockCipher(s)
+	return s
+}
+
+// ctr encrypts given input with CTR mode using tag (with MSB set) as initial counter block.
+// The first 32 bit of counter block is treated as little-endian counter.
+func (g *GCMSIV) ctr(enc *Block, tag, in []byte) []byte {
+	counter := make([]byte, gcmSIVBlockSize)
+	copy(counter, tag)
+	counter[gcmSIVBlockSize-1] |= 0x80
+
+	numOfBlocks := enc.numOfBlocks(len(in))
+	out := make([]byte, len(in))
+	for i := 0; i < numOfBlocks; i++ {
+		from := i * gcmSIVBlockSize
+		to := (i + 1) * gcmSIVBlockSize
+		if to > len(in) {
+			to = len(in)
+		}
+
+		state := make([]byte, gcmSIVBlockSize)
+		copy(state, counter)
+		enc.blockCipher(state)
+		// XOR with encrypted counter block
+		for j := from; j < to; j++ {
+			out[j] = in[j] ^ state[j-from]
+		}
+
+		c := binary.LittleEndian.Uint32(counter)
+		binary.LittleEndian.PutUint32(counter, c+1)
+	}
+	return out
+}
+
+// polyval calculates POLYVAL(H, A || 0^v || P || 0^u || LE64(len(A)) || LE64(len(P)))
+func polyval(h, additionalData, plaintext []byte) []byte {
+	paddedLength := func(n int) int {
+		return (n + gcmSIVBlockSize - 1) / gcmSIVBlockSize * gcmSIVBlockSize
+	}
+
+	in := make([]byte, paddedLength(len(additionalData))+paddedLength(len(plaintext))+gcmSIVBlockSize)
+	copy(in, additionalData)
+	copy(in[paddedLength(len(additionalData)):], plaintext)
+	lengths := in[len(in)-gcmSIVBlockSize:]
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+	return polyvalBlocks(h, in)
+}
+
+// polyvalBlocks calculates POLYVAL(H, X_1, ..., X_n) of given blocks.
+// POLYVAL is computed with GHASH multiplication as described in RFC 8452 Appendix A:
+// POLYVAL(H, X_1, ..., X_n) = ByteReverse(GHASH(mulX_GHASH(ByteReverse(H)), ByteReverse(X_1), ..., ByteReverse(X_n)))
+func polyvalBlocks(h, in []byte) []byte {
+	// mulX_GHASH(ByteReverse(H))
+	hh := reverseBytes(h)
+	lsb := hh[gcmSIVBlockSize-1] & 1
+	for j := gcmSIVBlockSize - 1; j > 0; j-- {
+		hh[j] = hh[j]>>1 | hh[j-1]<<7
+	}
+	hh[0] >>= 1
+	if lsb == 1 {
+		hh[0] ^= 0xe1
+	}
+
+	s := make([]byte, gcmSIVBlockSize)
+	for from := 0; from < len(in); from += gcmSIVBlockSize {
+		x := reverseBytes(in[from : from+gcmSIVBlockSize])
+		for j := 0; j < gcmSIVBlockSize; j++ {
+			s[j] ^= x[j]
+		}
+		s = gfMul128(s, hh)
+	}
+	return reverseBytes(s)
+}
+
+// reverseBytes returns byte-reversed copy of given slice
+func reverseBytes(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i := range in {
+		out[len(in)-1-i] = in[i]
+	}
+	return out
+}
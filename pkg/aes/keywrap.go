@@ -0,0 +1,174 @@
+package aes
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// keyWrapSemiblockSize is the size (byte) of semiblock used in key wrap
+	keyWrapSemiblockSize = 8
+	// keyWrapMaximumPadLength is the maximum length (byte) of plain text wrapped with padding
+	keyWrapMaximumPadLength = 1<<32 - 1
+)
+
+var (
+	// keyWrapIV is the default initial value defined in RFC 3394 Section 2.2.3.1
+	keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+	// keyWrapPadIV is the constant part of alternative initial value defined in RFC 5649 Section 3
+	keyWrapPadIV = []byte{0xa6, 0x59, 0x59, 0xa6}
+)
+
+// ErrIntegrity is returned when integrity check of unwrapped key fails
+var ErrIntegrity = errors.New("key unwrap integrity check failed")
+
+// Wrap wraps given key with key-encryption key b as defined in RFC 3394.
+// The key must be a multiple of 64 bit and at least 128 bit.
+func Wrap(b *Block, in []byte) ([]byte, error) {
+	if b.BlockSize() != 2*keyWrapSemiblockSize {
+		return nil, ErrBlockSize
+	}
+	if len(in) < 2*keyWrapSemiblockSize || len(in)%keyWrapSemiblockSize != 0 {
+		return nil, ErrPlaintextLength
+	}
+	return wrap(b, keyWrapIV, in), nil
+}
+
+// Unwrap unwraps given wrapped key with key-encryption key b as defined in RFC 3394
+func Unwrap(b *Block, in []byte) ([]byte, error) {
+	if b.BlockSize() != 2*keyWrapSemiblockSize {
+		return nil, ErrBlockSize
+	}
+	if len(in) < 3*keyWrapSemiblockSize || len(in)%keyWrapSemiblockSize != 0 {
+		return nil, ErrCiphertextLength
+	}
+
+	a, out := unwrap(b, in)
+	if subtle.ConstantTimeCompare(a, keyWrapIV) != 1 {
+		return nil, ErrIntegrity
+	}
+	return out, nil
+}
+
+// WrapPad wraps given key of any length with key-encryption key b as defined in RFC 5649
+func WrapPad(b *Block, in []byte) ([]byte, error) {
+	if b.BlockSize() != 2*keyWrapSemiblockSize {
+		return nil, ErrBlockSize
+	}
+	if len(in) == 0 || uint64(len(in)) > keyWrapMaximumPadLength {
+		return nil, ErrPlaintextLength
+	}
+
+	// AIV = A65959A6 || MLI (32 bit big-endian length of key in bytes)
+	aiv := make([]byte, keyWrapSemiblockSize)
+	copy(aiv, keyWrapPadIV)
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(in)))
+
+	padded := make([]byte, (len(in)+keyWrapSemiblockSize-1)/keyWrapSemiblockSize*keyWrapSemiblockSize)
+	copy(padded, in)
+
+	if len(padded) == keyWrapSemiblockSize {
+		// single semiblock is encrypted with AES in ECB mode
+		out := make([]byte, 2*keyWrapSemiblockSize)
+		copy(out, aiv)
+		copy(out[keyWrapSemiblockSize:], padded)
+		b.blockCipher(out)
+		return out, nil
+	}
+	return wrap(b, aiv, padded), nil
+}
+
+// UnwrapPad unwraps given wrapped key with key-encryption key b as defined in RFC 5649
+func UnwrapPad(b *Block, in []byte) ([]byte, error) {
+	if b.BlockSize() != 2*keyWrapSemiblockSize {
+		return nil, ErrBlockSize
+	}
+	if len(in) < 2*keyWrapSemiblockSize || len(in)%keyWrapSemiblockSize != 0 {
+		return nil, ErrCiphertextLength
+	}
+
+	var a, out []byte
+	if len(in) == 2*keyWrapSemiblockSize {
+		state := make([]byte, 2*keyWrapSemiblockSize)
+		copy(state, in)
+		b.invBlockCipher(state)
+		a, out = state[:keyWrapSemiblockSize], state[keyWrapSemiblockSize:]
+	} else {
+		a, out = unwrap(b, in)
+	}
+
+	// check AIV, MLI and padding
+	valid := subtle.ConstantTimeCompare(a[:4], keyWrapPadIV)
+	mli := int(binary.BigEndian.Uint32(a[4:]))
+	if mli <= len(out)-keyWrapSemiblockSize || mli > len(out) {
+		return nil, ErrIntegrity
+	}
+	var pad byte
+	for _, p := range out[mli:] {
+		pad |= p
+	}
+	valid &= subtle.ConstantTimeByteEq(pad, 0)
+	if valid != 1 {
+		return nil, ErrIntegrity
+	}
+	return out[:mli], nil
+}
+
+// wrap calculates wrapping process W(S) of RFC 3394 Section 2.2.1 with given initial value
+func wrap(b *Block, iv, in []byte) []byte {
+	n := len(in) / keyWrapSemiblockSize
+	out := make([]byte, keyWrapSemiblockSize+len(in))
+	copy(out[keyWrapSemiblockSize:], in)
+
+	a := make([]byte, keyWrapSemiblockSize)
+	copy(a, iv)
+	state := make([]byte, 2*keyWrapSemiblockSize)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := out[i*keyWrapSemiblockSize : (i+1)*keyWrapSemiblockSize]
+
+			// B = AES(K, A | R[i])
+			copy(state, a)
+			copy(state[keyWrapSemiblockSize:], r)
+			b.blockCipher(state)
+
+			// A = MSB(64, B) ^ t where t = (n*j)+i
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(state[:keyWrapSemiblockSize])^t)
+			// R[i] = LSB(64, B)
+			copy(r, state[keyWrapSemiblockSize:])
+		}
+	}
+	copy(out, a)
+	return out
+}
+
+// unwrap calculates unwrapping process W^-1(C) of RFC 3394 Section 2.2.2.
+// It returns recovered initial value and key.
+func unwrap(b *Block, in []byte) ([]byte, []byte) {
+	n := len(in)/keyWrapSemiblockSize - 1
+	out := make([]byte, len(in)-keyWrapSemiblockSize)
+	copy(out, in[keyWrapSemiblockSize:])
+
+	a := make([]byte, keyWrapSemiblockSize)
+	copy(a, in)
+	state := make([]byte, 2*keyWrapSemiblockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := out[(i-1)*keyWrapSemiblockSize : i*keyWrapSemiblockSize]
+
+			// B = AES-1(K, (A ^ t) | R[i]) where t = n*j+i
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(state, binary.BigEndian.Uint64(a)^t)
+			copy(state[keyWrapSemiblockSize:], r)
+			b.invBlockCipher(state)
+
+			// A = MSB(64, B)
+			copy(a, state[:keyWrapSemiblockSize])
+			// R[i] = LSB(64, B)
+			copy(r, state[keyWrapSemiblockSize:])
+		}
+	}
+	return a, out
+}
@@ -1,168 +1,146 @@
 package aes
 
-import (
-	"encoding/binary"
-	"log"
-)
+// ECBCipher encrypts given plain text with ECB mode.
+// Plain text is padded with given padding; nil selects PKCS7Padding.
+func ECBCipher(b *Block, in []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+
+	in, err := defaultPadding(padding).Pad(in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	numOfBlocks := b.numOfBlocks(len(in))
 
-// ECBCipher encrypts given plain text with ECB mode
-func ECBCipher(in, key []byte, numOfBlocks int) []byte {
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
+	out := make([]byte, numOfBlocks*blockSize)
 
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+		state := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
+		copy(state, in[from:to])
 
-		stateLength := to - from
-		if i == numOfBlocks-1 && to > len(in) {
-			stateLength = len(in) - from
-		}
-		copy(state, in[from:from+stateLength])
-
-		// add padding if need
-		if stateLength < Nb*BytesOfWords {
-			padding := Nb*BytesOfWords - stateLength
-			for i := stateLength; i < Nb*BytesOfWords; i++ {
-				state[i] = byte(padding)
-			}
-		}
-
-		blockCipher(state, key)
-		copy(out[from:from+Nb*BytesOfWords], state)
+		b.blockCipher(state)
+		copy(out[from:to], state)
 	}
-	return out
+	return out, nil
 }
 
-// ECBInvCipher decrypts given cipher text with ECB mode
-func ECBInvCipher(in, key []byte, numOfBlocks int) []byte {
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
+// ECBInvCipher decrypts given cipher text with ECB mode and removes given padding
+func ECBInvCipher(b *Block, in []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
 
-	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return nil, ErrCiphertextLength
+	}
 
-		stateLength := to - from
-		if i == numOfBlocks-1 && to > len(in) {
-			stateLength = len(in) - from
-		}
-		copy(state, in[from:from+stateLength])
+	out := make([]byte, numOfBlocks*blockSize)
 
-		invBlockCipher(state, key)
-		copy(out[from:from+Nb*BytesOfWords], state)
-	}
+	for i := 0; i < numOfBlocks; i++ {
+		state := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
+		copy(state, in[from:to])
 
-	// determine last byte (to remove padding)
-	var end int
-	if int(out[len(out)-1]) < Nb*BytesOfWords {
-		padding := int(out[len(out)-1])
-		end = len(out) - padding
-	} else {
-		end = len(out)
+		b.invBlockCipher(state)
+		copy(out[from:to], state)
 	}
-	return out[:end]
+	return defaultPadding(padding).Unpad(out, blockSize)
 }
 
-// CBCCipher encrypts given plain text with CBC mode
-func CBCCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	if len(iv) != Nb*BytesOfWords {
-		log.Fatalf("IV must be same as block size (%d byte)", Nb*BytesOfWords)
+// CBCCipher encrypts given plain text with CBC mode.
+// Plain text is padded with given padding; nil selects PKCS7Padding.
+func CBCCipher(b *Block, in, iv []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	in, err := defaultPadding(padding).Pad(in, blockSize)
+	if err != nil {
+		return nil, err
 	}
+	numOfBlocks := b.numOfBlocks(len(in))
 
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
-	previous := make([]byte, Nb*BytesOfWords)
+	out := make([]byte, numOfBlocks*blockSize)
+	previous := make([]byte, blockSize)
 	copy(previous, iv)
 
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
-
-		stateLength := to - from
-		if i == numOfBlocks-1 && to > len(in) {
-			stateLength = len(in) - from
-		}
-		copy(state, in[from:from+stateLength])
-
-		// add padding if need
-		if stateLength < Nb*BytesOfWords {
-			padding := Nb*BytesOfWords - stateLength
-			for i := stateLength; i < Nb*BytesOfWords; i++ {
-				state[i] = byte(padding)
-			}
-		}
+		state := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
+		copy(state, in[from:to])
 
 		// XOR with previous cipher block
-		for j := 0; j < Nb*BytesOfWords; j++ {
+		for j := 0; j < blockSize; j++ {
 			state[j] ^= previous[j]
 		}
 
-		blockCipher(state, key)
-		copy(out[from:from+Nb*BytesOfWords], state)
+		b.blockCipher(state)
+		copy(out[from:to], state)
 		// save cipher block for next block
 		copy(previous, state)
 	}
-	return out
+	return out, nil
 }
 
-// CBCInvCipher decrypts given cipher text with CBC mode
-func CBCInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	if len(iv) != Nb*BytesOfWords {
-		log.Fatalf("IV must be same as block size (%d byte)", Nb*BytesOfWords)
+// CBCInvCipher decrypts given cipher text with CBC mode and removes given padding
+func CBCInvCipher(b *Block, in, iv []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return nil, ErrCiphertextLength
 	}
 
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
-	previous := make([]byte, Nb*BytesOfWords)
+	out := make([]byte, numOfBlocks*blockSize)
+	previous := make([]byte, blockSize)
 	copy(previous, iv)
 
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		tmp := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
-
-		stateLength := to - from
-		if i == numOfBlocks-1 && to > len(in) {
-			stateLength = len(in) - from
-		}
-		copy(state, in[from:from+stateLength])
+		state := make([]byte, blockSize)
+		tmp := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
+		copy(state, in[from:to])
 		copy(tmp, state)
 
-		invBlockCipher(state, key)
+		b.invBlockCipher(state)
 		// XOR with previous cipher block
-		for j := 0; j < Nb*BytesOfWords; j++ {
+		for j := 0; j < blockSize; j++ {
 			state[j] ^= previous[j]
 		}
-		copy(out[from:from+Nb*BytesOfWords], state)
+		copy(out[from:to], state)
 		copy(previous, tmp)
 	}
-
-	// determine last byte (to remove padding)
-	var end int
-	if int(out[len(out)-1]) < Nb*BytesOfWords {
-		padding := int(out[len(out)-1])
-		end = len(out) - padding
-	} else {
-		end = len(out)
-	}
-	return out[:end]
+	return defaultPadding(padding).Unpad(out, blockSize)
 }
 
-// CBCCTSCipher decrypts given cipher text with CBC-CTS mode
-func CBCCTSCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	if len(iv) != Nb*BytesOfWords {
-		log.Fatalf("IV must be same as block size (%d byte)", Nb*BytesOfWords)
+// CBCCTSCipher encrypts given plain text with CBC-CTS mode.
+// Plain text must be longer than one block.
+func CBCCTSCipher(b *Block, in, iv []byte) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	if len(in) <= blockSize {
+		return nil, ErrPlaintextLength
 	}
 
 	out := make([]byte, len(in))
-	previous := make([]byte, Nb*BytesOfWords)
+	previous := make([]byte, blockSize)
 	copy(previous, iv)
 
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+		state := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
 
 		stateLength := to - from
 		if i == numOfBlocks-1 {
@@ -177,9 +155,9 @@ func CBCCTSCipher(in, key, iv []byte, numOfBlocks int) []byte {
 			state[j] ^= previous[j]
 		}
 
-		blockCipher(state, key)
+		b.blockCipher(state)
 		if i == numOfBlocks-1 {
-			copy(out[from-Nb*BytesOfWords:from], state)
+			copy(out[from-blockSize:from], state)
 			copy(out[from:from+stateLength], previous[:stateLength])
 		} else {
 			copy(out[from:from+stateLength], state)
@@ -187,27 +165,34 @@ func CBCCTSCipher(in, key, iv []byte, numOfBlocks int) []byte {
 			copy(previous, state)
 		}
 	}
-	return out
+	return out, nil
 }
 
-// CBCCTSInvCipher decrypts given cipher text with CBC-CTS mode
-func CBCCTSInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	if len(iv) != Nb*BytesOfWords {
-		log.Fatalf("IV must be same as block size (%d byte)", Nb*BytesOfWords)
+// CBCCTSInvCipher decrypts given cipher text with CBC-CTS mode.
+// Cipher text must be longer than one block.
+func CBCCTSInvCipher(b *Block, in, iv []byte) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	if len(in) <= blockSize {
+		return nil, ErrCiphertextLength
 	}
 
 	out := make([]byte, len(in))
-	previous := make([]byte, Nb*BytesOfWords)
+	previous := make([]byte, blockSize)
 
 	copy(previous, iv)
-	stateLength := Nb * BytesOfWords
+	stateLength := blockSize
 	for i := 0; i < numOfBlocks-2; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		tmp := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
+		state := make([]byte, blockSize)
+		tmp := make([]byte, blockSize)
+		from := i * blockSize
 		copy(state, in[from:from+stateLength])
 		copy(tmp, state)
-		invBlockCipher(state, key)
+		b.invBlockCipher(state)
 		// XOR with previous cipher block
 		for j := 0; j < stateLength; j++ {
 			state[j] ^= previous[j]
@@ -217,12 +202,15 @@ func CBCCTSInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
 	}
 
 	// stateN is last cipher block & n-1th plain text block
-	stateN := make([]byte, Nb*BytesOfWords)
-	stateN1 := make([]byte, Nb*BytesOfWords)
-	lastBlockLength := len(in) % (Nb * BytesOfWords)
+	stateN := make([]byte, blockSize)
+	stateN1 := make([]byte, blockSize)
+	lastBlockLength := len(in) % blockSize
+	if lastBlockLength == 0 {
+		lastBlockLength = blockSize
+	}
 
 	copy(stateN1, in[len(in)-lastBlockLength-stateLength:len(in)-lastBlockLength])
-	invBlockCipher(stateN1, key)
+	b.invBlockCipher(stateN1)
 	copy(stateN[:lastBlockLength], in[len(in)-lastBlockLength:])
 	copy(stateN[lastBlockLength:], stateN1[lastBlockLength:])
 
@@ -232,27 +220,34 @@ func CBCCTSInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
 	}
 	copy(out[len(in)-lastBlockLength:], stateN1[:lastBlockLength])
 
-	invBlockCipher(stateN, key)
+	b.invBlockCipher(stateN)
 	// XOR with previous cipher block
 	for j := 0; j < stateLength; j++ {
 		stateN[j] ^= previous[j]
 	}
 	copy(out[len(in)-lastBlockLength-stateLength:len(in)-lastBlockLength], stateN)
 
-	return out[:len(in)]
+	return out[:len(in)], nil
 }
 
 // CFBCipher encrypts given plain text with CFB mode
-func CFBCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
-	previous := make([]byte, Nb*BytesOfWords)
+func CFBCipher(b *Block, in, iv []byte) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+
+	out := make([]byte, numOfBlocks*blockSize)
+	previous := make([]byte, blockSize)
 	copy(previous, iv)
 
 	var end int
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+		state := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
 
 		stateLength := to - from
 		if i == numOfBlocks-1 && to > len(in) {
@@ -260,33 +255,40 @@ func CFBCipher(in, key, iv []byte, numOfBlocks int) []byte {
 		}
 		copy(state, in[from:from+stateLength])
 
-		blockCipher(previous, key)
+		b.blockCipher(previous)
 		// XOR with previous cipher block
-		for j := 0; j < Nb*BytesOfWords; j++ {
+		for j := 0; j < blockSize; j++ {
 			state[j] ^= previous[j]
 		}
-		copy(out[from:from+Nb*BytesOfWords], state)
+		copy(out[from:from+blockSize], state)
 		copy(previous, state)
 
-		if stateLength < Nb*BytesOfWords {
-			end = Nb*BytesOfWords - stateLength
+		if stateLength < blockSize {
+			end = blockSize - stateLength
 		}
 	}
-	return out[:len(out)-end]
+	return out[:len(out)-end], nil
 }
 
 // CFBInvCipher decrypts given cipher text with CFB mode
-func CFBInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
-	previous := make([]byte, Nb*BytesOfWords)
+func CFBInvCipher(b *Block, in, iv []byte) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+
+	out := make([]byte, numOfBlocks*blockSize)
+	previous := make([]byte, blockSize)
 	copy(previous, iv)
 
 	var end int
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		tmp := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+		state := make([]byte, blockSize)
+		tmp := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
 
 		stateLength := to - from
 		if i == numOfBlocks-1 && to > len(in) {
@@ -295,72 +297,114 @@ func CFBInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
 		copy(state, in[from:from+stateLength])
 		copy(tmp, state)
 
-		blockCipher(previous, key)
+		b.blockCipher(previous)
 		// XOR with previous cipher block
-		for j := 0; j < Nb*BytesOfWords; j++ {
+		for j := 0; j < blockSize; j++ {
 			state[j] ^= previous[j]
 		}
-		copy(out[from:from+Nb*BytesOfWords], state)
+		copy(out[from:from+blockSize], state)
 		copy(previous, tmp)
 
-		if stateLength < Nb*BytesOfWords {
-			end = Nb*BytesOfWords - stateLength
+		if stateLength < blockSize {
+			end = blockSize - stateLength
 		}
 	}
-	return out[:len(out)-end]
+	return out[:len(out)-end], nil
 }
 
-// OFBCipher encrypts given plain text with OFB mode
-func OFBCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
-	previous := make([]byte, Nb*BytesOfWords)
-	copy(previous, iv)
+// CFBSegmentCipher encrypts given plain text with CFB mode of given segment size (bit).
+// Segment size must be 1 or a multiple of 8 up to block size, e.g. 1 (CFB-1), 8 (CFB-8), 64 or 128.
+func CFBSegmentCipher(b *Block, in, iv []byte, segmentSize int) ([]byte, error) {
+	return cfbSegment(b, in, iv, segmentSize, false)
+}
 
-	var end int
-	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+// CFBSegmentInvCipher decrypts given cipher text with CFB mode of given segment size (bit)
+func CFBSegmentInvCipher(b *Block, in, iv []byte, segmentSize int) ([]byte, error) {
+	return cfbSegment(b, in, iv, segmentSize, true)
+}
 
-		stateLength := to - from
-		if i == numOfBlocks-1 && to > len(in) {
-			stateLength = len(in) - from
+// cfbSegment encrypts or decrypts given input with CFB mode of given segment size (bit)
+func cfbSegment(b *Block, in, iv []byte, segmentSize int, decrypt bool) ([]byte, error) {
+	blockSize := b.BlockSize()
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	if segmentSize != 1 && (segmentSize%8 != 0 || segmentSize < 8 || segmentSize > blockSize*8) {
+		return nil, ErrSegmentSize
+	}
+
+	out := make([]byte, len(in))
+	register := make([]byte, blockSize)
+	copy(register, iv)
+	state := make([]byte, blockSize)
+
+	if segmentSize == 1 {
+		// process each bit from the most significant bit
+		for i := 0; i < len(in)*8; i++ {
+			copy(state, register)
+			b.blockCipher(state)
+
+			inBit := in[i/8] >> uint(7-i%8) & 1
+			outBit := inBit ^ state[0]>>7
+			out[i/8] |= outBit << uint(7-i%8)
+
+			cipherBit := outBit
+			if decrypt {
+				cipherBit = inBit
+			}
+			// shift register left by 1 bit and feed cipher text bit back
+			for j := 0; j < blockSize-1; j++ {
+				register[j] = register[j]<<1 | register[j+1]>>7
+			}
+			register[blockSize-1] = register[blockSize-1]<<1 | cipherBit
 		}
-		copy(state, in[from:from+stateLength])
+		return out, nil
+	}
 
-		blockCipher(previous, key)
-		// XOR with previous cipher block
-		for j := 0; j < Nb*BytesOfWords; j++ {
-			state[j] ^= previous[j]
+	segment := segmentSize / 8
+	for from := 0; from < len(in); from += segment {
+		to := from + segment
+		if to > len(in) {
+			to = len(in)
+		}
+
+		copy(state, register)
+		b.blockCipher(state)
+		// XOR with the most significant bytes of encrypted register
+		for j := from; j < to; j++ {
+			out[j] = in[j] ^ state[j-from]
 		}
-		copy(out[from:from+Nb*BytesOfWords], state)
 
-		if stateLength < Nb*BytesOfWords {
-			end = Nb*BytesOfWords - stateLength
+		cipherSegment := out[from:to]
+		if decrypt {
+			cipherSegment = in[from:to]
 		}
+		// shift register left by segment and feed cipher text segment back
+		copy(register, register[segment:])
+		copy(register[blockSize-segment:], cipherSegment)
 	}
-	return out[:len(out)-end]
+	return out, nil
 }
 
-// OFBInvCipher decrypts given cipher text with OFB mode
-func OFBInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	return OFBCipher(in, key, iv, numOfBlocks)
-}
+// OFBCipher encrypts given plain text with OFB mode
+func OFBCipher(b *Block, in, iv []byte) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
 
-// CTRCipher encrypts given plain text with CTR mode
-func CTRCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	out := make([]byte, numOfBlocks*Nb*BytesOfWords)
-	previous := make([]byte, Nb*BytesOfWords)
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
 
-	nonce := make([]byte, (Nb/2)*BytesOfWords)
-	copy(nonce, iv[:(Nb/2)*BytesOfWords])
-	counter := binary.BigEndian.Uint64(iv[(Nb/2)*BytesOfWords:])
+	out := make([]byte, numOfBlocks*blockSize)
+	previous := make([]byte, blockSize)
+	copy(previous, iv)
 
 	var end int
 	for i := 0; i < numOfBlocks; i++ {
-		state := make([]byte, Nb*BytesOfWords)
-		from := i * Nb * BytesOfWords
-		to := (i + 1) * Nb * BytesOfWords
+		state := make([]byte, blockSize)
+		from := i * blockSize
+		to := (i + 1) * blockSize
 
 		stateLength := to - from
 		if i == numOfBlocks-1 && to > len(in) {
@@ -368,24 +412,33 @@ func CTRCipher(in, key, iv []byte, numOfBlocks int) []byte {
 		}
 		copy(state, in[from:from+stateLength])
 
-		copy(previous, nonce)
-		binary.BigEndian.PutUint64(previous[(Nb/2)*BytesOfWords:], uint64(counter))
-		blockCipher(previous, key)
+		b.blockCipher(previous)
 		// XOR with previous cipher block
-		for j := 0; j < Nb*BytesOfWords; j++ {
+		for j := 0; j < blockSize; j++ {
 			state[j] ^= previous[j]
 		}
-		copy(out[from:from+Nb*BytesOfWords], state)
-		counter++
+		copy(out[from:from+blockSize], state)
 
-		if stateLength < Nb*BytesOfWords {
-			end = Nb*BytesOfWords - stateLength
+		if stateLength < blockSize {
+			end = blockSize - stateLength
 		}
 	}
-	return out[:len(out)-end]
+	return out[:len(out)-end], nil
+}
+
+// OFBInvCipher decrypts given cipher text with OFB mode
+func OFBInvCipher(b *Block, in, iv []byte) ([]byte, error) {
+	return OFBCipher(b, in, iv)
+}
+
+// CTRCipher encrypts given plain text with CTR mode.
+// Counter block is 64 bit nonce followed by 64 bit big-endian counter taken from iv.
+// Use CTRCipherWithConfig for other counter layouts.
+func CTRCipher(b *Block, in, iv []byte) ([]byte, error) {
+	return CTRCipherWithConfig(b, in, iv, CTRConfig{})
 }
 
 // CTRInvCipher decrypts given cipher text with CTR mode
-func CTRInvCipher(in, key, iv []byte, numOfBlocks int) []byte {
-	return CTRCipher(in, key, iv, numOfBlocks)
+func CTRInvCipher(b *Block, in, iv []byte) ([]byte, error) {
+	return CTRCipher(b, in, iv)
 }
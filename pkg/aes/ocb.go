@@ -0,0 +1,270 @@
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+const (
+	// ocbBlockSize is the block size (byte) of OCB. It's fixed to 128 bit.
+	ocbBlockSize = 16
+	// ocbStandardNonceSize is the recommended nonce size (byte) of OCB
+	ocbStandardNonceSize = 12
+	// ocbMaximumNonceSize is the maximum nonce size (byte) of OCB
+	ocbMaximumNonceSize = 15
+	// ocbTagSize is the default (and maximum) tag size (byte) of OCB
+	ocbTagSize = 16
+	// ocbNumOfL is the number of precomputed L_i. ntz(i) never exceeds 63 for block index i.
+	ocbNumOfL = 64
+)
+
+// OCB is an AES-OCB3 instance defined in RFC 7253.
+// It implements crypto/cipher.AEAD.
+type OCB struct {
+	b       *Block
+	tagSize int
+	lStar   []byte   // L_* = ENCIPHER(K, zeros(128))
+	lDollar []byte   // L_$ = double(L_*)
+	l       [][]byte // L_i = double(L_{i-1}) where L_{-1} = L_$
+}
+
+var _ cipher.AEAD = (*OCB)(nil)
+
+// NewOCB returns OCB with given tag size.
+// Tag size must be between 1 and 16 bytes.
+func NewOCB(b *Block, tagSize int) (*OCB, error) {
+	if b.BlockSize() != ocbBlockSize {
+		return nil, ErrBlockSize
+	}
+	if tagSize < 1 || tagSize > ocbTagSize {
+		return nil, ErrTagSize
+	}
+
+	lStar := make([]byte, ocbBlockSize)
+	b.blockCipher(lStar)
+	lDollar := Xtime128(lStar)
+	l := make([][]byte, ocbNumOfL)
+	l[0] = Xtime128(lDollar)
+	for i := 1; i < ocbNumOfL; i++ {
+		l[i] = Xtime128(l[i-1])
+	}
+	return &OCB{
+		b:       b,
+		tagSize: tagSize,
+		lStar:   lStar,
+		lDollar: lDollar,
+		l:       l,
+	}, nil
+}
+
+// NonceSize returns the recommended nonce size.
+// Seal and Open accept nonce of 1 to 15 bytes.
+func (o *OCB) NonceSize() int {
+	return ocbStandardNonceSize
+}
+
+// Overhead returns the tag size
+func (o *OCB) Overhead() int {
+	return o.tagSize
+}
+
+// Seal encrypts and authenticates plaintext, authenticates additionalData
+// and appends the result (cipher text followed by tag) to dst
+func (o *OCB) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) == 0 || len(nonce) > ocbMaximumNonceSize {
+		panic("aes: incorrect nonce length given to OCB")
+	}
+
+	out, checksum, offset := o.crypt(nonce, plaintext, false)
+	tag := o.tag(checksum, offset, additionalData)
+
+	dst = append(dst, out...)
+	return append(dst, tag...)
+}
+
+// Open decrypts and verifies ciphertext (cipher text followed by tag) with additionalData
+// and appends the plain text to dst. Plain text is not released if authentication fails.
+func (o *OCB) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) == 0 || len(nonce) > ocbMaximumNonceSize {
+		panic("aes: incorrect nonce length given to OCB")
+	}
+	if len(ciphertext) < o.tagSize {
+		return nil, ErrAuthentication
+	}
+
+	tag := ciphertext[len(ciphertext)-o.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-o.tagSize]
+
+	plaintext, checksum, offset := o.crypt(nonce, ciphertext, true)
+	expectedTag := o.tag(checksum, offset, additionalData)
+	if subtle.ConstantTimeCompare(tag, expectedTag) != 1 {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+		return nil, ErrAuthentication
+	}
+	return append(dst, plaintext...), nil
+}
+
+// crypt encrypts or decrypts given input and returns the result, checksum of plain text and final offset
+func (o *OCB) crypt(nonce, in []byte, decrypt bool) ([]byte, []byte, []byte) {
+	out := make([]byte, len(in))
+	checksum := make([]byte, ocbBlockSize)
+	offset := o.initialOffset(nonce)
+
+	numOfFullBlocks := len(in) / ocbBlockSize
+	for i := 1; i <= numOfFullBlocks; i++ {
+		from := (i - 1) * ocbBlockSize
+		to := i * ocbBlockSize
+
+		// Offset_i = Offset_{i-1} xor L_{ntz(i)}
+		li := o.l[ntz(i)]
+		for j := 0; j < ocbBlockSize; j++ {
+			offset[j] ^= li[j]
+		}
+
+		state := make([]byte, ocbBlockSize)
+		for j := 0; j < ocbBlockSize; j++ {
+			state[j] = in[from+j] ^ offset[j]
+		}
+		if decrypt {
+			o.b.invBlockCipher(state)
+		} else {
+			o.b.blockCipher(state)
+		}
+		for j := 0; j < ocbBlockSize; j++ {
+			out[from+j] = state[j] ^ offset[j]
+		}
+
+		plain := out[from:to]
+		if !decrypt {
+			plain = in[from:to]
+		}
+		for j := 0; j < ocbBlockSize; j++ {
+			checksum[j] ^= plain[j]
+		}
+	}
+
+	if from := numOfFullBlocks * ocbBlockSize; from < len(in) {
+		// Offset_* = Offset_m xor L_*
+		for j := 0; j < ocbBlockSize; j++ {
+			offset[j] ^= o.lStar[j]
+		}
+		pad := make([]byte, ocbBlockSize)
+		copy(pad, offset)
+		o.b.blockCipher(pad)
+		for j := from; j < len(in); j++ {
+			out[j] = in[j] ^ pad[j-from]
+		}
+
+		plain := out[from:]
+		if !decrypt {
+			plain = in[from:]
+		}
+		// Checksum_* = Checksum_m xor (P_* || 1 || zeros(127-bitlen(P_*)))
+		for j := 0; j < len(plain); j++ {
+			checksum[j] ^= plain[j]
+		}
+		checksum[len(plain)] ^= 0x80
+	}
+	return out, checksum, offset
+}
+
+// tag calculates Tag = ENCIPHER(K, Checksum xor Offset xor L_$) xor HASH(K, A)
+func (o *OCB) tag(checksum, offset, additionalData []byte) []byte {
+	tag := make([]byte, ocbBlockSize)
+	for j := 0; j < ocbBlockSize; j++ {
+		tag[j] = checksum[j] ^ offset[j] ^ o.lDollar[j]
+	}
+	o.b.blockCipher(tag)
+
+	sum := o.hash(additionalData)
+	for j := 0; j < ocbBlockSize; j++ {
+		tag[j] ^= sum[j]
+	}
+	return tag[:o.tagSize]
+}
+
+// hash calculates HASH(K, A) of given additional data
+func (o *OCB) hash(additionalData []byte) []byte {
+	sum := make([]byte, ocbBlockSize)
+	offset := make([]byte, ocbBlockSize)
+
+	numOfFullBlocks := len(additionalData) / ocbBlockSize
+	for i := 1; i <= numOfFullBlocks; i++ {
+		from := (i - 1) * ocbBlockSize
+
+		li := o.l[ntz(i)]
+		state := make([]byte, ocbBlockSize)
+		for j := 0; j < ocbBlockSize; j++ {
+			offset[j] ^= li[j]
+			state[j] = additionalData[from+j] ^ offset[j]
+		}
+		o.b.blockCipher(state)
+		for j := 0; j < ocbBlockSize; j++ {
+			sum[j] ^= state[j]
+		}
+	}
+
+	if from := numOfFullBlocks * ocbBlockSize; from < len(additionalData) {
+		state := make([]byte, ocbBlockSize)
+		copy(state, additionalData[from:])
+		state[len(additionalData)-from] = 0x80
+		for j := 0; j < ocbBlockSize; j++ {
+			offset[j] ^= o.lStar[j]
+			state[j] ^= offset[j]
+		}
+		o.b.blockCipher(state)
+		for j := 0; j < ocbBlockSize; j++ {
+			sum[j] ^= state[j]
+		}
+	}
+	return sum
+}
+
+// initialOffset calculates Offset_0 from given nonce
+func (o *OCB) initialOffset(nonce []byte) []byte {
+	// Nonce = num2str(TAGLEN mod 128, 7) || zeros(120-bitlen(N)) || 1 || N
+	formatted := make([]byte, ocbBlockSize)
+	formatted[0] = byte((o.tagSize * 8 % 128) << 1)
+	formatted[ocbBlockSize-1-len(nonce)] |= 0x01
+	copy(formatted[ocbBlockSize-len(nonce):], nonce)
+
+	// bottom = str2num(Nonce[123..128])
+	bottom := uint(formatted[ocbBlockSize-1] & 0x3f)
+
+	// Ktop = ENCIPHER(K, Nonce[1..122] || zeros(6))
+	ktop := make([]byte, ocbBlockSize)
+	copy(ktop, formatted)
+	ktop[ocbBlockSize-1] &= 0xc0
+	o.b.blockCipher(ktop)
+
+	// Stretch = Ktop || (Ktop[1..64] xor Ktop[9..72])
+	stretch := make([]byte, ocbBlockSize+8)
+	copy(stretch, ktop)
+	for j := 0; j < 8; j++ {
+		stretch[ocbBlockSize+j] = ktop[j] ^ ktop[j+1]
+	}
+
+	// Offset_0 = Stretch[1+bottom..128+bottom]
+	offset := make([]byte, ocbBlockSize)
+	byteShift := bottom / 8
+	bitShift := bottom % 8
+	for j := 0; j < ocbBlockSize; j++ {
+		offset[j] = stretch[uint(j)+byteShift] << bitShift
+		if bitShift != 0 {
+			offset[j] |= stretch[uint(j)+byteShift+1] >> (8 - bitShift)
+		}
+	}
+	return offset
+}
+
+// ntz returns the number of trailing zero bits of given positive integer
+func ntz(i int) int {
+	n := 0
+	for i&1 == 0 {
+		i >>= 1
+		n++
+	}
+	return n
+}
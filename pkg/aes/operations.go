@@ -1,26 +1,87 @@
 package aes
 
-func keyExpansion(key []byte, expanded []byte) {
+func (b *Block) keyExpansion(key []byte) {
+	expanded := b.expandedKey
 	copy(expanded, key)
 
 	rc := byte(1) // round constant
 
-	for i := Nk; i < Nb*(Nr+1); i++ {
+	for i := b.nk; i < b.nb*(b.nr+1); i++ {
 		tmp := make([]byte, 4)
 		copy(tmp, expanded[i*4-BytesOfWords:i*4]) // copy previous word from expanded key to tmp
-		if i%Nk == 0 {
+		if i%b.nk == 0 {
 			rotWord(tmp)
-			subWord(tmp)
+			b.subBytes(tmp)
 			tmp[0] ^= rc
-			rc = Mul(rc, 2)
-		} else if Nk > 6 && i%Nk == 4 {
+			rc = mul(rc, 2, b.fieldPoly())
+		} else if b.nk > 6 && i%b.nk == 4 {
+			b.subBytes(tmp)
+		}
+
+		for j := 0; j < BytesOfWords; j++ {
+			expanded[i*4+j] = expanded[(i-b.nk)*4+j] ^ tmp[j]
+		}
+	}
+}
+
+// InvertKeySchedule recovers the cipher key from Nk consecutive words of the expanded key.
+// The length of window (16, 24 or 32 byte) decides the key size and position is the index of its first word,
+// so the round key of round N starts at word 4N. Each word is recovered as w[i-Nk] = w[i] XOR f(w[i-1])
+// where f is the transformation applied in keyExpansion.
+func InvertKeySchedule(window []byte, position int) ([]byte, error) {
+	var nk int
+	switch len(window) {
+	case 16:
+		nk = KeyLength128
+	case 24:
+		nk = KeyLength192
+	case 32:
+		nk = KeyLength256
+	default:
+		return nil, ErrKeySize
+	}
+	// the expanded key has Nb * (Nr + 1) words where Nr = Nk + 6
+	if position < 0 || position+nk > BlockSize128*(nk+6+1) {
+		return nil, ErrKeyPosition
+	}
+
+	w := make([]byte, (position+nk)*BytesOfWords)
+	copy(w[position*BytesOfWords:], window)
+	for i := position + nk - 1; i >= nk; i-- {
+		tmp := make([]byte, 4)
+		copy(tmp, w[i*4-BytesOfWords:i*4])
+		if i%nk == 0 {
+			rotWord(tmp)
+			subWord(tmp)
+			tmp[0] ^= rcon(i / nk)
+		} else if nk > 6 && i%nk == 4 {
 			subWord(tmp)
 		}
 
 		for j := 0; j < BytesOfWords; j++ {
-			expanded[i*4+j] = expanded[(i-Nk)*4+j] ^ tmp[j]
+			w[(i-nk)*4+j] = w[i*4+j] ^ tmp[j]
 		}
 	}
+	return w[:nk*BytesOfWords], nil
+}
+
+// rcon returns the round constant x^(i-1) in GF(2^8)
+func rcon(i int) byte {
+	rc := byte(1)
+	for j := 1; j < i; j++ {
+		rc = Xtime(rc)
+	}
+	return rc
+}
+
+// eqInvKeyExpansion transforms expanded key into the decryption key schedule of the equivalent inverse cipher.
+// InvMixColumns is applied to every round key except the first and the last one (FIPS-197 Section 5.3.5).
+func (b *Block) eqInvKeyExpansion() {
+	b.eqInvKey = make([]byte, len(b.expandedKey))
+	copy(b.eqInvKey, b.expandedKey)
+	for round := 1; round < b.nr; round++ {
+		b.invMixColumns(b.eqInvRoundKey(round))
+	}
 }
 
 func rotWord(word []byte) {
@@ -32,84 +93,105 @@ func rotWord(word []byte) {
 }
 
 func subWord(word []byte) {
-	for i := 0; i < BytesOfWords; i++ {
-		x := word[i] >> 4
-		y := word[i] & 0xf
-		word[i] = sbox[x][y]
-	}
+	substitute(word[:BytesOfWords], &sbox)
 }
 
 // SubBytes transforms given state with sbox
 func SubBytes(state []byte) {
-	for i := 0; i < Nb*BytesOfWords; i++ {
-		x := state[i] >> 4
-		y := state[i] & 0xf
-		state[i] = sbox[x][y]
+	substitute(state, &sbox)
+}
+
+// shiftOffset returns the number of bytes which row r is shifted by ShiftRows in the state of nb columns.
+// Offsets are 1, 2, 3 for Nb = 4, 5, 6 and change to 1, 2, 4 for Nb = 7 and 1, 3, 4 for Nb = 8.
+func shiftOffset(r, nb int) int {
+	switch {
+	case nb == 8 && r >= 2:
+		return r + 1
+	case nb == 7 && r == 3:
+		return r + 1
 	}
+	return r
 }
 
 // ShiftRows transforms given state with byte shift
 func ShiftRows(state []byte) {
-	l := len(state)
-	t := make([]byte, l)
+	nb := len(state) / BytesOfWords
+	t := make([]byte, len(state))
 	copy(t, state)
 
-	for y := 0; y < Nb; y++ {
+	for y := 0; y < nb; y++ {
 		for x := 1; x < BytesOfWords; x++ {
-			state[x+y*Nb] = t[x+Nb*((x+y)%BytesOfWords)]
+			state[x+y*BytesOfWords] = t[x+BytesOfWords*((y+shiftOffset(x, nb))%nb)]
 		}
 	}
 }
 
 // MixColumns transforms given state with multiplication in a Golois Field
 func MixColumns(state []byte) {
-	l := len(state)
-	tmp := make([]byte, l)
+	mixColumns(state, &polyMatrix, poly)
+}
+
+// mixColumns multiplies every column of given state by matrix in GF(2^8) defined by p
+func mixColumns(state []byte, matrix *[4][4]byte, p int) {
+	nb := len(state) / BytesOfWords
+	tmp := make([]byte, len(state))
 	copy(tmp, state)
 
-	for y := 0; y < Nb; y++ {
-		for x := 0; x < l/Nb; x++ {
-			state[y*BytesOfWords+x] = Mul(polyMatrix[x][0], tmp[y*BytesOfWords]) ^ Mul(polyMatrix[x][1], tmp[y*BytesOfWords+1]) ^ Mul(polyMatrix[x][2], tmp[y*BytesOfWords+2]) ^ Mul(polyMatrix[x][3], tmp[y*BytesOfWords+3])
+	for y := 0; y < nb; y++ {
+		for x := 0; x < BytesOfWords; x++ {
+			state[y*BytesOfWords+x] = mul(matrix[x][0], tmp[y*BytesOfWords], p) ^ mul(matrix[x][1], tmp[y*BytesOfWords+1], p) ^ mul(matrix[x][2], tmp[y*BytesOfWords+2], p) ^ mul(matrix[x][3], tmp[y*BytesOfWords+3], p)
 		}
 	}
 }
 
+// mixColumns transforms given state with MixColumns in the field of b
+func (b *Block) mixColumns(state []byte) {
+	mixColumns(state, &polyMatrix, b.fieldPoly())
+}
+
+// invMixColumns transforms given state with InvMixColumns in the field of b
+func (b *Block) invMixColumns(state []byte) {
+	if b.variant == nil {
+		InvMixColumns(state)
+		return
+	}
+	mixColumns(state, &b.variant.invMix, b.variant.poly)
+}
+
+// fieldPoly returns the polynomial which defines GF(2^8) of b
+func (b *Block) fieldPoly() int {
+	if b.variant == nil {
+		return poly
+	}
+	return b.variant.poly
+}
+
 // AddRoundKey transforms given state with XOR to round key
 func AddRoundKey(state, key []byte) {
-	for i := 0; i < Nb*BytesOfWords; i++ {
+	for i := 0; i < len(state); i++ {
 		state[i] ^= key[i]
 	}
 }
 
 // InvShiftRows transforms given state with byte shift
 func InvShiftRows(state []byte) {
-	tmp := make([]byte, Nb*BytesOfWords)
+	nb := len(state) / BytesOfWords
+	tmp := make([]byte, len(state))
 	copy(tmp, state)
 
-	for y := 0; y < Nb; y++ {
+	for y := 0; y < nb; y++ {
 		for x := 1; x < BytesOfWords; x++ {
-			state[x+y*BytesOfWords] = tmp[x+BytesOfWords*((y+Nb-x)%BytesOfWords)]
+			state[x+y*BytesOfWords] = tmp[x+BytesOfWords*((y+nb-shiftOffset(x, nb))%nb)]
 		}
 	}
 }
 
 // InvSubBytes transforms given state with sbox
 func InvSubBytes(state []byte) {
-	for i := 0; i < Nb*BytesOfWords; i++ {
-		x := state[i] >> 4
-		y := state[i] & 0xf
-		state[i] = invSbox[x][y]
-	}
+	substitute(state, &invSbox)
 }
 
 // InvMixColumns transforms given state with multiplication in a Golois Field
 func InvMixColumns(state []byte) {
-	tmp := make([]byte, Nb*BytesOfWords)
-	copy(tmp, state)
-
-	for y := 0; y < Nb; y++ {
-		for x := 0; x < BytesOfWords; x++ {
-			state[y*BytesOfWords+x] = Mul(invPolyMatrix[x][0], tmp[y*BytesOfWords]) ^ Mul(invPolyMatrix[x][1], tmp[y*BytesOfWords+1]) ^ Mul(invPolyMatrix[x][2], tmp[y*BytesOfWords+2]) ^ Mul(invPolyMatrix[x][3], tmp[y*BytesOfWords+3])
-		}
-	}
+	mixColumns(state, &invPolyMatrix, poly)
 }
@@ -0,0 +1,211 @@
+package aes
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+)
+
+// Padding pads plain text to a multiple of block size before encryption
+// and removes the padding after decryption. It's used by ECB and CBC modes.
+type Padding interface {
+	// Pad returns a copy of given input with padding appended
+	Pad(in []byte, blockSize int) ([]byte, error)
+	// Unpad returns given input without padding. It returns ErrInvalidPadding if padding is malformed.
+	Unpad(in []byte, blockSize int) ([]byte, error)
+}
+
+var (
+	_ Padding = PKCS7Padding{}
+	_ Padding = ANSIX923Padding{}
+	_ Padding = ISO78164Padding{}
+	_ Padding = ISO10126Padding{}
+	_ Padding = ZeroPadding{}
+	_ Padding = NoPadding{}
+)
+
+// defaultPadding returns PKCS7Padding if given padding is nil
+func defaultPadding(padding Padding) Padding {
+	if padding == nil {
+		return PKCS7Padding{}
+	}
+	return padding
+}
+
+// paddingLength returns the number of padding bytes needed for always-pad schemes.
+// A full block is added when input is a multiple of block size.
+func paddingLength(n, blockSize int) int {
+	return blockSize - n%blockSize
+}
+
+// lastPaddingByte checks the length of padded input and returns the last byte as padding length
+func lastPaddingByte(in []byte, blockSize int) (int, error) {
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return 0, ErrInvalidPadding
+	}
+	padding := int(in[len(in)-1])
+	if padding == 0 || padding > blockSize {
+		return 0, ErrInvalidPadding
+	}
+	return padding, nil
+}
+
+// PKCS7Padding is the padding defined in RFC 5652 (PKCS #7).
+// Every padding byte has the value of padding length and padding is always added.
+type PKCS7Padding struct{}
+
+// Pad appends PKCS #7 padding
+func (PKCS7Padding) Pad(in []byte, blockSize int) ([]byte, error) {
+	padding := paddingLength(len(in), blockSize)
+	out := make([]byte, len(in)+padding)
+	copy(out, in)
+	for i := len(in); i < len(out); i++ {
+		out[i] = byte(padding)
+	}
+	return out, nil
+}
+
+// Unpad removes PKCS #7 padding after checking every padding byte
+func (PKCS7Padding) Unpad(in []byte, blockSize int) ([]byte, error) {
+	padding, err := lastPaddingByte(in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	valid := 1
+	for _, p := range in[len(in)-padding:] {
+		valid &= subtle.ConstantTimeByteEq(p, byte(padding))
+	}
+	if valid != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return in[:len(in)-padding], nil
+}
+
+// ANSIX923Padding is the padding defined in ANSI X9.23.
+// Padding is zeros followed by a byte of padding length and is always added.
+type ANSIX923Padding struct{}
+
+// Pad appends ANSI X9.23 padding
+func (ANSIX923Padding) Pad(in []byte, blockSize int) ([]byte, error) {
+	padding := paddingLength(len(in), blockSize)
+	out := make([]byte, len(in)+padding)
+	copy(out, in)
+	out[len(out)-1] = byte(padding)
+	return out, nil
+}
+
+// Unpad removes ANSI X9.23 padding after checking zero bytes
+func (ANSIX923Padding) Unpad(in []byte, blockSize int) ([]byte, error) {
+	padding, err := lastPaddingByte(in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	valid := 1
+	for _, p := range in[len(in)-padding : len(in)-1] {
+		valid &= subtle.ConstantTimeByteEq(p, 0)
+	}
+	if valid != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return in[:len(in)-padding], nil
+}
+
+// ISO78164Padding is the padding defined in ISO/IEC 7816-4.
+// Padding is a byte 0x80 followed by zeros and is always added.
+type ISO78164Padding struct{}
+
+// Pad appends ISO/IEC 7816-4 padding
+func (ISO78164Padding) Pad(in []byte, blockSize int) ([]byte, error) {
+	padding := paddingLength(len(in), blockSize)
+	out := make([]byte, len(in)+padding)
+	copy(out, in)
+	out[len(in)] = 0x80
+	return out, nil
+}
+
+// Unpad removes ISO/IEC 7816-4 padding
+func (ISO78164Padding) Unpad(in []byte, blockSize int) ([]byte, error) {
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	// search 0x80 in the last block skipping trailing zeros
+	for i := len(in) - 1; i >= len(in)-blockSize; i-- {
+		switch in[i] {
+		case 0x00:
+			continue
+		case 0x80:
+			return in[:i], nil
+		}
+		break
+	}
+	return nil, ErrInvalidPadding
+}
+
+// ISO10126Padding is the padding defined in ISO 10126.
+// Padding is random bytes followed by a byte of padding length and is always added.
+type ISO10126Padding struct{}
+
+// Pad appends ISO 10126 padding
+func (ISO10126Padding) Pad(in []byte, blockSize int) ([]byte, error) {
+	padding := paddingLength(len(in), blockSize)
+	out := make([]byte, len(in)+padding)
+	copy(out, in)
+	if _, err := rand.Read(out[len(in) : len(out)-1]); err != nil {
+		return nil, err
+	}
+	out[len(out)-1] = byte(padding)
+	return out, nil
+}
+
+// Unpad removes ISO 10126 padding. Only padding length can be checked.
+func (ISO10126Padding) Unpad(in []byte, blockSize int) ([]byte, error) {
+	padding, err := lastPaddingByte(in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return in[:len(in)-padding], nil
+}
+
+// ZeroPadding pads with zeros only when input is not a multiple of block size.
+// Trailing zeros of plain text are indistinguishable from padding and removed on Unpad.
+type ZeroPadding struct{}
+
+// Pad appends zeros up to block boundary
+func (ZeroPadding) Pad(in []byte, blockSize int) ([]byte, error) {
+	padding := 0
+	if len(in)%blockSize != 0 {
+		padding = paddingLength(len(in), blockSize)
+	}
+	out := make([]byte, len(in)+padding)
+	copy(out, in)
+	return out, nil
+}
+
+// Unpad removes trailing zeros of the last block
+func (ZeroPadding) Unpad(in []byte, blockSize int) ([]byte, error) {
+	if len(in)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	n := len(in)
+	for n > 0 && n > len(in)-blockSize+1 && in[n-1] == 0 {
+		n--
+	}
+	return in[:n], nil
+}
+
+// NoPadding doesn't add padding. Input must be a multiple of block size.
+type NoPadding struct{}
+
+// Pad returns a copy of given input. It returns ErrPlaintextLength if input is not a multiple of block size.
+func (NoPadding) Pad(in []byte, blockSize int) ([]byte, error) {
+	if len(in)%blockSize != 0 {
+		return nil, ErrPlaintextLength
+	}
+	out := make([]byte, len(in))
+	copy(out, in)
+	return out, nil
+}
+
+// Unpad returns given input as is
+func (NoPadding) Unpad(in []byte, blockSize int) ([]byte, error) {
+	return in, nil
+}
@@ -0,0 +1,181 @@
+package aes
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelChunkBlocks is the number of blocks processed by a worker at once
+const parallelChunkBlocks = 256
+
+// parallelBlocks splits numOfBlocks blocks into chunks and calls fn for each chunk
+// on a worker pool of GOMAXPROCS goroutines. fn receives the block range [from, to).
+// It returns the error of the first chunk which failed.
+func parallelBlocks(numOfBlocks int, fn func(from, to int) error) error {
+	numOfChunks := (numOfBlocks + parallelChunkBlocks - 1) / parallelChunkBlocks
+	workers := runtime.GOMAXPROCS(0)
+	if workers > numOfChunks {
+		workers = numOfChunks
+	}
+
+	chunks := make(chan int, numOfChunks)
+	for i := 0; i < numOfChunks; i++ {
+		chunks <- i
+	}
+	close(chunks)
+
+	errs := make([]error, numOfChunks)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range chunks {
+				from := i * parallelChunkBlocks
+				to := from + parallelChunkBlocks
+				if to > numOfBlocks {
+					to = numOfBlocks
+				}
+				errs[i] = fn(from, to)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ECBCipherParallel encrypts given plain text with ECB mode on multiple goroutines.
+// The result is the same as ECBCipher.
+func ECBCipherParallel(b *Block, in []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+
+	in, err := defaultPadding(padding).Pad(in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	out := make([]byte, numOfBlocks*blockSize)
+	copy(out, in)
+	parallelBlocks(numOfBlocks, func(from, to int) error {
+		for i := from; i < to; i++ {
+			b.blockCipher(out[i*blockSize : (i+1)*blockSize])
+		}
+		return nil
+	})
+	return out, nil
+}
+
+// ECBInvCipherParallel decrypts given cipher text with ECB mode on multiple goroutines.
+// The result is the same as ECBInvCipher.
+func ECBInvCipherParallel(b *Block, in []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return nil, ErrCiphertextLength
+	}
+
+	out := make([]byte, numOfBlocks*blockSize)
+	copy(out, in)
+	parallelBlocks(numOfBlocks, func(from, to int) error {
+		for i := from; i < to; i++ {
+			b.invBlockCipher(out[i*blockSize : (i+1)*blockSize])
+		}
+		return nil
+	})
+	return defaultPadding(padding).Unpad(out, blockSize)
+}
+
+// CBCInvCipherParallel decrypts given cipher text with CBC mode on multiple goroutines.
+// Every block depends only on cipher text, so CBC decryption can be parallelized unlike encryption.
+// The result is the same as CBCInvCipher.
+func CBCInvCipherParallel(b *Block, in, iv []byte, padding Padding) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	if len(iv) != blockSize {
+		return nil, ErrIVSize
+	}
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return nil, ErrCiphertextLength
+	}
+
+	out := make([]byte, numOfBlocks*blockSize)
+	copy(out, in)
+	parallelBlocks(numOfBlocks, func(from, to int) error {
+		for i := from; i < to; i++ {
+			state := out[i*blockSize : (i+1)*blockSize]
+			b.invBlockCipher(state)
+
+			previous := iv
+			if i > 0 {
+				previous = in[(i-1)*blockSize : i*blockSize]
+			}
+			// XOR with previous cipher block
+			for j := 0; j < blockSize; j++ {
+				state[j] ^= previous[j]
+			}
+		}
+		return nil
+	})
+	return defaultPadding(padding).Unpad(out, blockSize)
+}
+
+// CTRCipherParallel encrypts given plain text with CTR mode on multiple goroutines.
+// The result is the same as CTRCipher.
+func CTRCipherParallel(b *Block, in, iv []byte) ([]byte, error) {
+	return CTRCipherWithConfigParallel(b, in, iv, CTRConfig{})
+}
+
+// CTRInvCipherParallel decrypts given cipher text with CTR mode on multiple goroutines
+func CTRInvCipherParallel(b *Block, in, iv []byte) ([]byte, error) {
+	return CTRCipherParallel(b, in, iv)
+}
+
+// CTRCipherWithConfigParallel encrypts given plain text with CTR mode of given counter layout on multiple goroutines.
+// Each chunk starts from its own counter block derived by adding the block index to config.Offset.
+// The result is the same as CTRCipherWithConfig.
+func CTRCipherWithConfigParallel(b *Block, in, iv []byte, config CTRConfig) ([]byte, error) {
+	blockSize := b.BlockSize()
+	numOfBlocks := b.numOfBlocks(len(in))
+
+	// validate parameters before starting workers
+	if _, err := newCTRCounter(b, iv, config); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(in))
+	err := parallelBlocks(numOfBlocks, func(from, to int) error {
+		chunkConfig := config
+		chunkConfig.Offset += uint64(from)
+		if chunkConfig.Offset < config.Offset {
+			return ErrCounterOverflow
+		}
+		c, err := newCTRCounter(b, iv, chunkConfig)
+		if err != nil {
+			return err
+		}
+
+		end := to * blockSize
+		if end > len(in) {
+			end = len(in)
+		}
+		return ctrXORBlocks(b, c, out[from*blockSize:end], in[from*blockSize:end])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CTRInvCipherWithConfigParallel decrypts given cipher text with CTR mode of given counter layout on multiple goroutines
+func CTRInvCipherWithConfigParallel(b *Block, in, iv []byte, config CTRConfig) ([]byte, error) {
+	return CTRCipherWithConfigParallel(b, in, iv, config)
+}
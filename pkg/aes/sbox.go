@@ -0,0 +1,190 @@
+package aes
+
+import (
+	"errors"
+)
+
+var (
+	// ErrSBoxPoly is returned when given polynomial is not irreducible of degree 8
+	ErrSBoxPoly = errors.New("S-box polynomial must be irreducible of degree 8")
+	// ErrSBoxAffine is returned when given affine transformation is not invertible
+	ErrSBoxAffine = errors.New("S-box affine transformation must be invertible")
+)
+
+// SBoxConfig represents parameters to generate S-box of Rijndael variants.
+// S-box maps b to A(b⁻¹) where b⁻¹ is the multiplicative inverse in GF(2^8) defined by Poly (0 is mapped to 0)
+// and A(x) = x * AffineMultiplier mod (x⁸ + 1) XOR AffineConstant.
+type SBoxConfig struct {
+	// Poly is the irreducible polynomial of degree 8 which defines GF(2^8).
+	// NewCipherWithSBox also uses the field in MixColumns and the round constants of the key expansion.
+	Poly int
+	// AffineMultiplier is the polynomial multiplied modulo x⁸ + 1.
+	// It must have an odd number of terms to be invertible.
+	AffineMultiplier byte
+	// AffineConstant is XORed after the multiplication
+	AffineConstant byte
+}
+
+// variant is the tables of Rijndael variant over GF(2^8) defined by its own polynomial
+type variant struct {
+	poly int
+	s    [256]byte
+	inv  [256]byte
+	// invMix is the InvMixColumns matrix in the field.
+	// MixColumns has the same coefficients as AES and its inverse is MixColumns³ in every field of characteristic 2.
+	invMix [4][4]byte
+}
+
+// sbox and invSbox are the AES S-box and inverse S-box generated from DefaultSBoxConfig
+var sbox, invSbox = mustGenerateSBox(DefaultSBoxConfig())
+
+// DefaultSBoxConfig returns the parameters of the AES S-box defined in FIPS-197 Section 5.1.1
+func DefaultSBoxConfig() SBoxConfig {
+	return SBoxConfig{
+		Poly:             poly,
+		AffineMultiplier: 0x1f, // 1 + x + x² + x³ + x⁴
+		AffineConstant:   0x63,
+	}
+}
+
+// GenerateSBox returns S-box and inverse S-box generated from given parameters
+func GenerateSBox(config SBoxConfig) ([]byte, []byte, error) {
+	t, err := generateSBox(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.s[:], t.inv[:], nil
+}
+
+// NewCipherWithSBox creates a new AES Block from given key which is the Rijndael variant of given parameters.
+// The S-box is used in both of the cipher and the key expansion, and every multiplication of MixColumns,
+// InvMixColumns and the round constants of the key expansion is done in GF(2^8) defined by config.Poly.
+func NewCipherWithSBox(key []byte, config SBoxConfig) (*Block, error) {
+	v, err := newVariant(config)
+	if err != nil {
+		return nil, err
+	}
+	return newBlock(key, BlockSize128*BytesOfWords, 0, ImplementationReference, v)
+}
+
+// newVariant generates the tables of Rijndael variant from given parameters
+func newVariant(config SBoxConfig) (*variant, error) {
+	t, err := generateSBox(config)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &variant{
+		poly: config.Poly,
+		s:    t.s,
+		inv:  t.inv,
+	}
+	square := mulMatrix(&polyMatrix, &polyMatrix, config.Poly)
+	v.invMix = mulMatrix(&square, &polyMatrix, config.Poly)
+	return v, nil
+}
+
+// mulMatrix multiplies 4x4 matrices in GF(2^8) defined by p
+func mulMatrix(m, n *[4][4]byte, p int) [4][4]byte {
+	var result [4][4]byte
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				result[i][j] ^= mul(m[i][k], n[k][j], p)
+			}
+		}
+	}
+	return result
+}
+
+// sboxTable is a pair of S-box and inverse S-box
+type sboxTable struct {
+	s   [256]byte
+	inv [256]byte
+}
+
+// generateSBox generates S-box from given parameters
+func generateSBox(config SBoxConfig) (*sboxTable, error) {
+	if !isIrreducible(config.Poly) {
+		return nil, ErrSBoxPoly
+	}
+
+	t := &sboxTable{}
+	seen := make([]bool, 256)
+	for x := 0; x < 256; x++ {
+		s := affine(inverse(byte(x), config.Poly), config.AffineMultiplier) ^ config.AffineConstant
+		if seen[s] {
+			return nil, ErrSBoxAffine
+		}
+		seen[s] = true
+		t.s[x] = s
+		t.inv[s] = byte(x)
+	}
+	return t, nil
+}
+
+// mustGenerateSBox returns S-box and inverse S-box generated from given parameters. It panics on error.
+func mustGenerateSBox(config SBoxConfig) ([256]byte, [256]byte) {
+	t, err := generateSBox(config)
+	if err != nil {
+		panic(err)
+	}
+	return t.s, t.inv
+}
+
+// inverse returns the multiplicative inverse of n in GF(2^8) defined by p as n²⁵⁴.
+// 0 is mapped to 0.
+func inverse(n byte, p int) byte {
+	result := byte(1)
+	for i := 0; i < 254; i++ {
+		result = mul(result, n, p)
+	}
+	return result
+}
+
+// affine multiplies n and m modulo x⁸ + 1, i.e. XORs n rotated by every term of m
+func affine(n, m byte) byte {
+	var result byte
+	for i := uint(0); i < 8; i++ {
+		if m>>i&1 == 1 {
+			result ^= n<<i | n>>(8-i)
+		}
+	}
+	return result
+}
+
+// isIrreducible returns true if p is an irreducible polynomial of degree 8 over GF(2).
+// It's enough to check that no polynomial of degree 1 to 4 divides p.
+func isIrreducible(p int) bool {
+	if p>>8 != 1 {
+		return false
+	}
+	for d := 2; d < 1<<5; d++ {
+		if polyMod(p, d) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// polyMod returns the remainder of polynomial division a / b over GF(2)
+func polyMod(a, b int) int {
+	degree := func(x int) int {
+		d := -1
+		for ; x != 0; x >>= 1 {
+			d++
+		}
+		return d
+	}
+	for db := degree(b); degree(a) >= db; {
+		a ^= b << uint(degree(a)-db)
+	}
+	return a
+}
+
+// substitute replaces every byte of state with given table
+func substitute(state []byte, table *[256]byte) {
+	for i := range state {
+		state[i] = table[state[i]]
+	}
+}
@@ -0,0 +1,168 @@
+package aes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// fips197Sbox is the S-box in FIPS-197 Figure 7
+var fips197Sbox = [][]byte{
+	[]byte{0x63, 0x7c, 0x77, 0x7b, 0xf2, 0x6b, 0x6f, 0xc5, 0x30, 0x01, 0x67, 0x2b, 0xfe, 0xd7, 0xab, 0x76},
+	[]byte{0xca, 0x82, 0xc9, 0x7d, 0xfa, 0x59, 0x47, 0xf0, 0xad, 0xd4, 0xa2, 0xaf, 0x9c, 0xa4, 0x72, 0xc0},
+	[]byte{0xb7, 0xfd, 0x93, 0x26, 0x36, 0x3f, 0xf7, 0xcc, 0x34, 0xa5, 0xe5, 0xf1, 0x71, 0xd8, 0x31, 0x15},
+	[]byte{0x04, 0xc7, 0x23, 0xc3, 0x18, 0x96, 0x05, 0x9a, 0x07, 0x12, 0x80, 0xe2, 0xeb, 0x27, 0xb2, 0x75},
+	[]byte{0x09, 0x83, 0x2c, 0x1a, 0x1b, 0x6e, 0x5a, 0xa0, 0x52, 0x3b, 0xd6, 0xb3, 0x29, 0xe3, 0x2f, 0x84},
+	[]byte{0x53, 0xd1, 0x00, 0xed, 0x20, 0xfc, 0xb1, 0x5b, 0x6a, 0xcb, 0xbe, 0x39, 0x4a, 0x4c, 0x58, 0xcf},
+	[]byte{0xd0, 0xef, 0xaa, 0xfb, 0x43, 0x4d, 0x33, 0x85, 0x45, 0xf9, 0x02, 0x7f, 0x50, 0x3c, 0x9f, 0xa8},
+	[]byte{0x51, 0xa3, 0x40, 0x8f, 0x92, 0x9d, 0x38, 0xf5, 0xbc, 0xb6, 0xda, 0x21, 0x10, 0xff, 0xf3, 0xd2},
+	[]byte{0xcd, 0x0c, 0x13, 0xec, 0x5f, 0x97, 0x44, 0x17, 0xc4, 0xa7, 0x7e, 0x3d, 0x64, 0x5d, 0x19, 0x73},
+	[]byte{0x60, 0x81, 0x4f, 0xdc, 0x22, 0x2a, 0x90, 0x88, 0x46, 0xee, 0xb8, 0x14, 0xde, 0x5e, 0x0b, 0xdb},
+	[]byte{0xe0, 0x32, 0x3a, 0x0a, 0x49, 0x06, 0x24, 0x5c, 0xc2, 0xd3, 0xac, 0x62, 0x91, 0x95, 0xe4, 0x79},
+	[]byte{0xe7, 0xc8, 0x37, 0x6d, 0x8d, 0xd5, 0x4e, 0xa9, 0x6c, 0x56, 0xf4, 0xea, 0x65, 0x7a, 0xae, 0x08},
+	[]byte{0xba, 0x78, 0x25, 0x2e, 0x1c, 0xa6, 0xb4, 0xc6, 0xe8, 0xdd, 0x74, 0x1f, 0x4b, 0xbd, 0x8b, 0x8a},
+	[]byte{0x70, 0x3e, 0xb5, 0x66, 0x48, 0x03, 0xf6, 0x0e, 0x61, 0x35, 0x57, 0xb9, 0x86, 0xc1, 0x1d, 0x9e},
+	[]byte{0xe1, 0xf8, 0x98, 0x11, 0x69, 0xd9, 0x8e, 0x94, 0x9b, 0x1e, 0x87, 0xe9, 0xce, 0x55, 0x28, 0xdf},
+	[]byte{0x8c, 0xa1, 0x89, 0x0d, 0xbf, 0xe6, 0x42, 0x68, 0x41, 0x99, 0x2d, 0x0f, 0xb0, 0x54, 0xbb, 0x16},
+}
+
+// fips197InvSbox is the inverse S-box in FIPS-197 Figure 14
+var fips197InvSbox = [][]byte{
+	[]byte{0x52, 0x09, 0x6a, 0xd5, 0x30, 0x36, 0xa5, 0x38, 0xbf, 0x40, 0xa3, 0x9e, 0x81, 0xf3, 0xd7, 0xfb},
+	[]byte{0x7c, 0xe3, 0x39, 0x82, 0x9b, 0x2f, 0xff, 0x87, 0x34, 0x8e, 0x43, 0x44, 0xc4, 0xde, 0xe9, 0xcb},
+	[]byte{0x54, 0x7b, 0x94, 0x32, 0xa6, 0xc2, 0x23, 0x3d, 0xee, 0x4c, 0x95, 0x0b, 0x42, 0xfa, 0xc3, 0x4e},
+	[]byte{0x08, 0x2e, 0xa1, 0x66, 0x28, 0xd9, 0x24, 0xb2, 0x76, 0x5b, 0xa2, 0x49, 0x6d, 0x8b, 0xd1, 0x25},
+	[]byte{0x72, 0xf8, 0xf6, 0x64, 0x86, 0x68, 0x98, 0x16, 0xd4, 0xa4, 0x5c, 0xcc, 0x5d, 0x65, 0xb6, 0x92},
+	[]byte{0x6c, 0x70, 0x48, 0x50, 0xfd, 0xed, 0xb9, 0xda, 0x5e, 0x15, 0x46, 0x57, 0xa7, 0x8d, 0x9d, 0x84},
+	[]byte{0x90, 0xd8, 0xab, 0x00, 0x8c, 0xbc, 0xd3, 0x0a, 0xf7, 0xe4, 0x58, 0x05, 0xb8, 0xb3, 0x45, 0x06},
+	[]byte{0xd0, 0x2c, 0x1e, 0x8f, 0xca, 0x3f, 0x0f, 0x02, 0xc1, 0xaf, 0xbd, 0x03, 0x01, 0x13, 0x8a, 0x6b},
+	[]byte{0x3a, 0x91, 0x11, 0x41, 0x4f, 0x67, 0xdc, 0xea, 0x97, 0xf2, 0xcf, 0xce, 0xf0, 0xb4, 0xe6, 0x73},
+	[]byte{0x96, 0xac, 0x74, 0x22, 0xe7, 0xad, 0x35, 0x85, 0xe2, 0xf9, 0x37, 0xe8, 0x1c, 0x75, 0xdf, 0x6e},
+	[]byte{0x47, 0xf1, 0x1a, 0x71, 0x1d, 0x29, 0xc5, 0x89, 0x6f, 0xb7, 0x62, 0x0e, 0xaa, 0x18, 0xbe, 0x1b},
+	[]byte{0xfc, 0x56, 0x3e, 0x4b, 0xc6, 0xd2, 0x79, 0x20, 0x9a, 0xdb, 0xc0, 0xfe, 0x78, 0xcd, 0x5a, 0xf4},
+	[]byte{0x1f, 0xdd, 0xa8, 0x33, 0x88, 0x07, 0xc7, 0x31, 0xb1, 0x12, 0x10, 0x59, 0x27, 0x80, 0xec, 0x5f},
+	[]byte{0x60, 0x51, 0x7f, 0xa9, 0x19, 0xb5, 0x4a, 0x0d, 0x2d, 0xe5, 0x7a, 0x9f, 0x93, 0xc9, 0x9c, 0xef},
+	[]byte{0xa0, 0xe0, 0x3b, 0x4d, 0xae, 0x2a, 0xf5, 0xb0, 0xc8, 0xeb, 0xbb, 0x3c, 0x83, 0x53, 0x99, 0x61},
+	[]byte{0x17, 0x2b, 0x04, 0x7e, 0xba, 0x77, 0xd6, 0x26, 0xe1, 0x69, 0x14, 0x63, 0x55, 0x21, 0x0c, 0x7d},
+}
+
+func TestGenerateSBox(t *testing.T) {
+	s, inv, err := GenerateSBox(DefaultSBoxConfig())
+	if err != nil {
+		t.Fatalf("[TestGenerateSBox] failed: %v", err)
+	}
+	for i := range fips197Sbox {
+		if !bytes.Equal(s[16*i:16*(i+1)], fips197Sbox[i]) {
+			t.Errorf("[TestGenerateSBox] row %x failed: sbox != expected : '%v' != '%v'", i, s[16*i:16*(i+1)], fips197Sbox[i])
+		}
+		if !bytes.Equal(inv[16*i:16*(i+1)], fips197InvSbox[i]) {
+			t.Errorf("[TestGenerateSBox] row %x failed: invSbox != expected : '%v' != '%v'", i, inv[16*i:16*(i+1)], fips197InvSbox[i])
+		}
+	}
+
+	invalids := []struct {
+		config   SBoxConfig
+		expected error
+	}{
+		{SBoxConfig{Poly: 0x1b, AffineMultiplier: 0x1f, AffineConstant: 0x63}, ErrSBoxPoly},
+		{SBoxConfig{Poly: 0x100, AffineMultiplier: 0x1f, AffineConstant: 0x63}, ErrSBoxPoly},
+		{SBoxConfig{Poly: 0x11f, AffineMultiplier: 0x1f, AffineConstant: 0x63}, ErrSBoxPoly},
+		{SBoxConfig{Poly: 0x11b, AffineMultiplier: 0x03, AffineConstant: 0x63}, ErrSBoxAffine},
+	}
+	for i, c := range invalids {
+		if _, _, err := GenerateSBox(c.config); !errors.Is(err, c.expected) {
+			t.Errorf("[TestGenerateSBox] case %d failed: err != expected : '%v' != '%v'", i, err, c.expected)
+		}
+	}
+}
+
+func TestCipherWithSBox(t *testing.T) {
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	plainText := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	expected := []byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a}
+
+	b, err := NewCipherWithSBox(key, DefaultSBoxConfig())
+	if err != nil {
+		t.Fatalf("[TestCipherWithSBox] failed: %v", err)
+	}
+	cipherText := make([]byte, 16)
+	b.Encrypt(cipherText, plainText)
+	if !bytes.Equal(cipherText, expected) {
+		t.Errorf("[TestCipherWithSBox] failed: cipherText != expected :\ncipher:\t\t%s\nexpected:\t%s", PrintableBytes(cipherText), PrintableBytes(expected))
+	}
+
+	// x⁸ + x⁴ + x³ + x² + 1 and x⁸ + x⁶ + x⁵ + x + 1 are also irreducible
+	configs := []SBoxConfig{
+		SBoxConfig{Poly: 0x11d, AffineMultiplier: 0x1f, AffineConstant: 0x00},
+		SBoxConfig{Poly: 0x163, AffineMultiplier: 0x07, AffineConstant: 0xa5},
+	}
+	for i, config := range configs {
+		b, err := NewCipherWithSBox(key, config)
+		if err != nil {
+			t.Fatalf("[TestCipherWithSBox] case %d failed: %v", i, err)
+		}
+		b.Encrypt(cipherText, plainText)
+		if bytes.Equal(cipherText, expected) {
+			t.Errorf("[TestCipherWithSBox] case %d failed: cipherText is same as AES", i)
+		}
+
+		decrypted := make([]byte, 16)
+		b.Decrypt(decrypted, cipherText)
+		if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestCipherWithSBox] case %d failed: plainText != expected :\nplain:\t\t%s\nexpected:\t%s", i, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+		b.EqInvCipher(decrypted, cipherText)
+		if !bytes.Equal(decrypted, plainText) {
+			t.Errorf("[TestCipherWithSBox] case %d failed: EqInvCipher != expected :\nplain:\t\t%s\nexpected:\t%s", i, PrintableBytes(decrypted), PrintableBytes(plainText))
+		}
+	}
+}
+
+func TestVariantField(t *testing.T) {
+	// InvMixColumns of the AES field is MixColumns³
+	v, err := newVariant(DefaultSBoxConfig())
+	if err != nil {
+		t.Fatalf("[TestVariantField] failed: %v", err)
+	}
+	if v.invMix != invPolyMatrix {
+		t.Errorf("[TestVariantField] failed: invMix != expected : %x != %x", v.invMix, invPolyMatrix)
+	}
+
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	state := []byte{0xdb, 0x13, 0x53, 0x45, 0xf2, 0x0a, 0x22, 0x5c, 0x01, 0x01, 0x01, 0x01, 0xc6, 0xc6, 0xc6, 0xc6}
+	b, err := NewCipherWithSBox(key, SBoxConfig{Poly: 0x11d, AffineMultiplier: 0x1f, AffineConstant: 0x63})
+	if err != nil {
+		t.Fatalf("[TestVariantField] failed: %v", err)
+	}
+
+	// MixColumns multiplies in the field of the variant
+	mixed := make([]byte, len(state))
+	copy(mixed, state)
+	b.mixColumns(mixed)
+	expected := make([]byte, len(state))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			for k := 0; k < 4; k++ {
+				expected[y*4+x] ^= mul(polyMatrix[x][k], state[y*4+k], 0x11d)
+			}
+		}
+	}
+	if !bytes.Equal(mixed, expected) {
+		t.Errorf("[TestVariantField] failed: mixed != expected :\nmixed:\t\t%s\nexpected:\t%s", PrintableBytes(mixed), PrintableBytes(expected))
+	}
+	b.invMixColumns(mixed)
+	if !bytes.Equal(mixed, state) {
+		t.Errorf("[TestVariantField] failed: InvMixColumns(MixColumns(state)) != state :\nresult:\t\t%s\nexpected:\t%s", PrintableBytes(mixed), PrintableBytes(state))
+	}
+
+	// the round constant of the 8th round is x⁷ and the 9th is x⁸ reduced by the polynomial
+	for i, expected := range map[int]byte{8: 0x80, 9: 0x1d, 10: 0x3a} {
+		w := b.expandedKey[(i*4-1)*BytesOfWords : i*4*BytesOfWords]
+		tmp := []byte{w[1], w[2], w[3], w[0]}
+		b.subBytes(tmp)
+		previous := b.expandedKey[(i-1)*4*BytesOfWords : (i-1)*4*BytesOfWords+BytesOfWords]
+		if rc := b.expandedKey[i*4*BytesOfWords] ^ previous[0] ^ tmp[0]; rc != expected {
+			t.Errorf("[TestVariantField] round %d failed: Rcon is %#x, but expected %#x", i, rc, expected)
+		}
+	}
+}
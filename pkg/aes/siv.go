@@ -0,0 +1,148 @@
+package aes
+
+import (
+	"crypto/subtle"
+)
+
+// sivBlockSize is the block size (byte) of SIV. It's fixed to 128 bit.
+const sivBlockSize = 16
+
+// SIV is an AES-SIV instance defined in RFC 5297.
+// It holds two AES keys: K1 for S2V and K2 for CTR.
+type SIV struct {
+	mac *CMAC
+	ctr *Block
+}
+
+// NewSIV creates SIV from given double-length key.
+// The key must be 32, 48 or 64 bytes to select AES-SIV-256, AES-SIV-384 or AES-SIV-512.
+func NewSIV(key []byte) (*SIV, error) {
+	if len(key) != 32 && len(key) != 48 && len(key) != 64 {
+		return nil, ErrKeySize
+	}
+
+	b, err := NewCipher(key[:len(key)/2])
+	if err != nil {
+		return nil, err
+	}
+	mac, err := NewCMAC(b, cmacTagSize)
+	if err != nil {
+		return nil, err
+	}
+	ctr, err := NewCipher(key[len(key)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return &SIV{
+		mac: mac,
+		ctr: ctr,
+	}, nil
+}
+
+// Overhead returns the size of synthetic IV prepended to cipher text
+func (s *SIV) Overhead() int {
+	return sivBlockSize
+}
+
+// Seal encrypts and authenticates plaintext, authenticates each component of additionalData
+// and appends the result (synthetic IV followed by cipher text) to dst.
+// For nonce-based use, pass the nonce as the last component of additionalData.
+func (s *SIV) Seal(dst, plaintext []byte, additionalData ...[]byte) []byte {
+	v := s.s2v(sivStrings(additionalData, plaintext))
+
+	dst = append(dst, v...)
+	return append(dst, s.ctrCrypt(v, plaintext)...)
+}
+
+// Open decrypts and verifies ciphertext (synthetic IV followed by cipher text) with each component
+// of additionalData and appends the plain text to dst. Plain text is not released if authentication fails.
+func (s *SIV) Open(dst, ciphertext []byte, additionalData ...[]byte) ([]byte, error) {
+	if len(ciphertext) < sivBlockSize {
+		return nil, ErrAuthentication
+	}
+
+	v := ciphertext[:sivBlockSize]
+	plaintext := s.ctrCrypt(v, ciphertext[sivBlockSize:])
+	expectedV := s.s2v(sivStrings(additionalData, plaintext))
+	if subtle.ConstantTimeCompare(v, expectedV) != 1 {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+		return nil, ErrAuthentication
+	}
+	return append(dst, plaintext...), nil
+}
+
+// sivStrings returns the input of S2V without modifying given additional data
+func sivStrings(additionalData [][]byte, plaintext []byte) [][]byte {
+	strings := make([][]byte, 0, len(additionalData)+1)
+	strings = append(strings, additionalData...)
+	return append(strings, plaintext)
+}
+
+// s2v calculates S2V of given strings. The last string is the plain text.
+func (s *SIV) s2v(strings [][]byte) []byte {
+	d := s.mac.checksum(make([]byte, sivBlockSize))
+	for _, str := range strings[:len(strings)-1] {
+		d = Xtime128(d)
+		mac := s.mac.checksum(str)
+		for j := 0; j < sivBlockSize; j++ {
+			d[j] ^= mac[j]
+		}
+	}
+
+	last := strings[len(strings)-1]
+	var t []byte
+	if len(last) >= sivBlockSize {
+		// T = Sn xorend D
+		t = make([]byte, len(last))
+		copy(t, last)
+		for j := 0; j < sivBlockSize; j++ {
+			t[len(t)-sivBlockSize+j] ^= d[j]
+		}
+	} else {
+		// T = dbl(D) xor pad(Sn)
+		t = Xtime128(d)
+		for j := 0; j < len(last); j++ {
+			t[j] ^= last[j]
+		}
+		t[len(last)] ^= 0x80
+	}
+	return s.mac.checksum(t)
+}
+
+// ctrCrypt encrypts given input with CTR mode using synthetic IV as 128 bit counter
+func (s *SIV) ctrCrypt(v, in []byte) []byte {
+	// clear 31st and 63rd bits (from the right) of counter
+	counter := make([]byte, sivBlockSize)
+	copy(counter, v)
+	counter[8] &= 0x7f
+	counter[12] &= 0x7f
+
+	numOfBlocks := s.ctr.numOfBlocks(len(in))
+	out := make([]byte, len(in))
+	for i := 0; i < numOfBlocks; i++ {
+		from := i * sivBlockSize
+		to := (i + 1) * sivBlockSize
+		if to > len(in) {
+			to = len(in)
+		}
+
+		state := make([]byte, sivBlockSize)
+		copy(state, counter)
+		s.ctr.blockCipher(state)
+		// XOR with encrypted counter block
+		for j := from; j < to; j++ {
+			out[j] = in[j] ^ state[j-from]
+		}
+
+		// increment counter as 128 bit big-endian integer
+		for j := sivBlockSize - 1; j >= 0; j-- {
+			counter[j]++
+			if counter[j] != 0 {
+				break
+			}
+		}
+	}
+	return out
+}
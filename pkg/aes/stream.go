@@ -0,0 +1,472 @@
+package aes
+
+import (
+	"io"
+)
+
+// streamChunkSize is the size (byte) of chunk read from underlying reader at once
+const streamChunkSize = 4096
+
+// keyStream encrypts or decrypts data byte by byte like stream cipher.
+// It's used for CTR, OFB and CFB modes including CFB-8 and CFB-1.
+type keyStream interface {
+	xorKeyStream(dst, src []byte) error
+}
+
+// ctrStream generates key stream of CTR mode.
+// Counter block is 64 bit nonce followed by 64 bit big-endian counter like CTRCipher.
+type ctrStream struct {
+	b       *Block
+	counter *ctrCounter
+	out     []byte
+	used    int
+	started bool
+}
+
+func newCTRStream(b *Block, iv []byte) (*ctrStream, error) {
+	counter, err := newCTRCounter(b, iv, CTRConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return &ctrStream{
+		b:       b,
+		counter: counter,
+		out:     make([]byte, len(iv)),
+		used:    len(iv),
+	}, nil
+}
+
+func (s *ctrStream) xorKeyStream(dst, src []byte) error {
+	blockSize := len(s.out)
+	for i := range src {
+		if s.used == blockSize {
+			if s.started {
+				if err := s.counter.inc(); err != nil {
+					return err
+				}
+			}
+			s.started = true
+			copy(s.out, s.counter.block)
+			s.b.blockCipher(s.out)
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.out[s.used]
+		s.used++
+	}
+	return nil
+}
+
+// ofbStream generates key stream of OFB mode
+type ofbStream struct {
+	b    *Block
+	out  []byte
+	used int
+}
+
+func newOFBStream(b *Block, iv []byte) *ofbStream {
+	out := make([]byte, len(iv))
+	copy(out, iv)
+	return &ofbStream{
+		b:    b,
+		out:  out,
+		used: len(iv),
+	}
+}
+
+func (s *ofbStream) xorKeyStream(dst, src []byte) error {
+	blockSize := len(s.out)
+	for i := range src {
+		if s.used == blockSize {
+			s.b.blockCipher(s.out)
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.out[s.used]
+		s.used++
+	}
+	return nil
+}
+
+// cfbStream generates key stream of CFB mode.
+// Cipher text is fed back after each full block.
+type cfbStream struct {
+	b       *Block
+	next    []byte // cipher text of current block which becomes next input
+	out     []byte
+	used    int
+	decrypt bool
+}
+
+func newCFBStream(b *Block, iv []byte, decrypt bool) *cfbStream {
+	next := make([]byte, len(iv))
+	copy(next, iv)
+	return &cfbStream{
+		b:       b,
+		next:    next,
+		out:     make([]byte, len(iv)),
+		used:    len(iv),
+		decrypt: decrypt,
+	}
+}
+
+func (s *cfbStream) xorKeyStream(dst, src []byte) error {
+	blockSize := len(s.out)
+	for i := range src {
+		if s.used == blockSize {
+			copy(s.out, s.next)
+			s.b.blockCipher(s.out)
+			s.used = 0
+		}
+		// save cipher text before dst may overwrite src
+		if s.decrypt {
+			s.next[s.used] = src[i]
+		}
+		dst[i] = src[i] ^ s.out[s.used]
+		if !s.decrypt {
+			s.next[s.used] = dst[i]
+		}
+		s.used++
+	}
+	return nil
+}
+
+// cfbSegmentStream generates key stream of CFB mode whose segment is shorter than a block.
+// Segment size is 8 (CFB-8) or 1 (CFB-1) bit; CFB-1 processes each byte bit by bit from the most significant bit.
+type cfbSegmentStream struct {
+	b           *Block
+	register    []byte
+	state       []byte
+	segmentSize int
+	decrypt     bool
+}
+
+func newCFBSegmentStream(b *Block, iv []byte, segmentSize int, decrypt bool) *cfbSegmentStream {
+	register := make([]byte, len(iv))
+	copy(register, iv)
+	return &cfbSegmentStream{
+		b:           b,
+		register:    register,
+		state:       make([]byte, len(iv)),
+		segmentSize: segmentSize,
+		decrypt:     decrypt,
+	}
+}
+
+func (s *cfbSegmentStream) xorKeyStream(dst, src []byte) error {
+	blockSize := len(s.register)
+	for i := range src {
+		in := src[i]
+		if s.segmentSize == 8 {
+			copy(s.state, s.register)
+			s.b.blockCipher(s.state)
+			dst[i] = in ^ s.state[0]
+
+			cipherByte := dst[i]
+			if s.decrypt {
+				cipherByte = in
+			}
+			// shift register left by 8 bit and feed cipher text byte back
+			copy(s.register, s.register[1:])
+			s.register[blockSize-1] = cipherByte
+			continue
+		}
+
+		var out byte
+		for k := uint(0); k < 8; k++ {
+			copy(s.state, s.register)
+			s.b.blockCipher(s.state)
+			inBit := in >> (7 - k) & 1
+			outBit := inBit ^ s.state[0]>>7
+			out |= outBit << (7 - k)
+
+			cipherBit := outBit
+			if s.decrypt {
+				cipherBit = inBit
+			}
+			// shift register left by 1 bit and feed cipher text bit back
+			for j := 0; j < blockSize-1; j++ {
+				s.register[j] = s.register[j]<<1 | s.register[j+1]>>7
+			}
+			s.register[blockSize-1] = s.register[blockSize-1]<<1 | cipherBit
+		}
+		dst[i] = out
+	}
+	return nil
+}
+
+// newKeyStream returns keyStream for given mode.
+// It returns nil if given mode is not a stream mode.
+func newKeyStream(b *Block, mode int, iv []byte, decrypt bool) (keyStream, error) {
+	switch mode {
+	case ModeCTR:
+		return newCTRStream(b, iv)
+	case ModeOFB:
+		return newOFBStream(b, iv), nil
+	case ModeCFB:
+		return newCFBStream(b, iv, decrypt), nil
+	case ModeCFB8:
+		return newCFBSegmentStream(b, iv, 8, decrypt), nil
+	case ModeCFB1:
+		return newCFBSegmentStream(b, iv, 1, decrypt), nil
+	}
+	return nil, nil
+}
+
+// checkStreamMode validates given mode and iv for streaming
+func checkStreamMode(b *Block, mode int, iv []byte) error {
+	switch mode {
+	case ModeECB, ModeCBC, ModeCFB, ModeCFB8, ModeCFB1, ModeOFB, ModeCTR:
+	default:
+		return ErrInvalidMode
+	}
+
+	// ECB mode doesn't use iv
+	if mode != ModeECB && len(iv) != b.BlockSize() {
+		return ErrIVSize
+	}
+	return nil
+}
+
+// encryptWriter encrypts data written to it and writes the result to underlying writer
+type encryptWriter struct {
+	w        io.Writer
+	b        *Block
+	stream   keyStream
+	padding  Padding
+	previous []byte // previous cipher block of CBC mode. It's nil in ECB mode.
+	buf      []byte // plain text not yet encrypted in ECB and CBC modes
+	closed   bool
+}
+
+// NewEncryptWriter returns io.WriteCloser which encrypts data chunk by chunk and writes it to w.
+// Mode must be one of ModeECB, ModeCBC, ModeCFB, ModeCFB8, ModeCFB1, ModeOFB and ModeCTR; iv is ignored in ECB mode.
+// Padding is used only in ECB and CBC modes; nil selects PKCS7Padding.
+// Close must be called to flush the last block; it does not close w.
+func NewEncryptWriter(w io.Writer, key []byte, mode int, iv []byte, padding Padding) (io.WriteCloser, error) {
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncryptWriterWithBlock(w, b, mode, iv, padding)
+}
+
+// NewEncryptWriterWithBlock returns io.WriteCloser like NewEncryptWriter which encrypts with given Block
+func NewEncryptWriterWithBlock(w io.Writer, b *Block, mode int, iv []byte, padding Padding) (io.WriteCloser, error) {
+	if err := checkStreamMode(b, mode, iv); err != nil {
+		return nil, err
+	}
+
+	stream, err := newKeyStream(b, mode, iv, false)
+	if err != nil {
+		return nil, err
+	}
+	e := &encryptWriter{
+		w:       w,
+		b:       b,
+		stream:  stream,
+		padding: defaultPadding(padding),
+	}
+	if mode == ModeCBC {
+		e.previous = make([]byte, len(iv))
+		copy(e.previous, iv)
+	}
+	return e, nil
+}
+
+// Write encrypts p and writes the result to underlying writer.
+// In ECB and CBC modes, incomplete last block is buffered until next Write or Close.
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if e.stream != nil {
+		out := make([]byte, len(p))
+		if err := e.stream.xorKeyStream(out, p); err != nil {
+			return 0, err
+		}
+		if _, err := e.w.Write(out); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	blockSize := e.b.BlockSize()
+	e.buf = append(e.buf, p...)
+	n := len(e.buf) / blockSize * blockSize
+	if n == 0 {
+		return len(p), nil
+	}
+	out := e.encryptBlocks(e.buf[:n])
+	e.buf = append(e.buf[:0], e.buf[n:]...)
+	if _, err := e.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes the last block with padding in ECB and CBC modes
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if e.stream != nil {
+		return nil
+	}
+
+	last, err := e.padding.Pad(e.buf, e.b.BlockSize())
+	if err != nil {
+		return err
+	}
+	e.buf = e.buf[:0]
+	if len(last) == 0 {
+		return nil
+	}
+	_, err = e.w.Write(e.encryptBlocks(last))
+	return err
+}
+
+// encryptBlocks encrypts given full blocks with ECB mode or CBC mode continuing from previous cipher block
+func (e *encryptWriter) encryptBlocks(in []byte) []byte {
+	blockSize := e.b.BlockSize()
+	out := make([]byte, len(in))
+	copy(out, in)
+	for from := 0; from < len(in); from += blockSize {
+		state := out[from : from+blockSize]
+		if e.previous == nil {
+			e.b.blockCipher(state)
+			continue
+		}
+		// XOR with previous cipher block
+		for j := 0; j < blockSize; j++ {
+			state[j] ^= e.previous[j]
+		}
+		e.b.blockCipher(state)
+		copy(e.previous, state)
+	}
+	return out
+}
+
+// decryptReader reads data from underlying reader and decrypts it
+type decryptReader struct {
+	r        io.Reader
+	b        *Block
+	stream   keyStream
+	padding  Padding
+	previous []byte // previous cipher block of CBC mode. It's nil in ECB mode.
+	buf      []byte // cipher text not yet decrypted in ECB and CBC modes
+	out      []byte // decrypted plain text not yet read
+	err      error
+}
+
+// NewDecryptReader returns io.Reader which reads data from r and decrypts it chunk by chunk.
+// Mode must be one of ModeECB, ModeCBC, ModeCFB, ModeCFB8, ModeCFB1, ModeOFB and ModeCTR; iv is ignored in ECB mode.
+// In ECB and CBC modes, the last block is held back until the end of r to remove given padding;
+// nil selects PKCS7Padding.
+func NewDecryptReader(r io.Reader, key []byte, mode int, iv []byte, padding Padding) (io.Reader, error) {
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewDecryptReaderWithBlock(r, b, mode, iv, padding)
+}
+
+// NewDecryptReaderWithBlock returns io.Reader like NewDecryptReader which decrypts with given Block
+func NewDecryptReaderWithBlock(r io.Reader, b *Block, mode int, iv []byte, padding Padding) (io.Reader, error) {
+	if err := checkStreamMode(b, mode, iv); err != nil {
+		return nil, err
+	}
+
+	stream, err := newKeyStream(b, mode, iv, true)
+	if err != nil {
+		return nil, err
+	}
+	d := &decryptReader{
+		r:       r,
+		b:       b,
+		stream:  stream,
+		padding: defaultPadding(padding),
+	}
+	if mode == ModeCBC {
+		d.previous = make([]byte, len(iv))
+		copy(d.previous, iv)
+	}
+	return d, nil
+}
+
+// Read reads and decrypts data into p
+func (d *decryptReader) Read(p []byte) (int, error) {
+	if d.stream != nil {
+		n, err := d.r.Read(p)
+		if xerr := d.stream.xorKeyStream(p[:n], p[:n]); xerr != nil {
+			return 0, xerr
+		}
+		return n, err
+	}
+
+	for len(d.out) == 0 && d.err == nil {
+		d.fill()
+	}
+	if len(d.out) == 0 {
+		return 0, d.err
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// fill reads next chunk from underlying reader and decrypts full blocks except the last one
+func (d *decryptReader) fill() {
+	blockSize := d.b.BlockSize()
+	chunk := make([]byte, streamChunkSize)
+	n, err := d.r.Read(chunk)
+	d.buf = append(d.buf, chunk[:n]...)
+
+	if err == nil {
+		// hold back the last block which may contain padding
+		m := (len(d.buf) - 1) / blockSize * blockSize
+		if m > 0 {
+			d.out = d.decryptBlocks(d.buf[:m])
+			d.buf = append(d.buf[:0], d.buf[m:]...)
+		}
+		return
+	}
+	if err != io.EOF {
+		d.err = err
+		return
+	}
+
+	d.err = io.EOF
+	if len(d.buf) == 0 || len(d.buf)%blockSize != 0 {
+		d.err = ErrCiphertextLength
+		return
+	}
+	out, err := d.padding.Unpad(d.decryptBlocks(d.buf), blockSize)
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.buf = d.buf[:0]
+	d.out = out
+}
+
+// decryptBlocks decrypts given full blocks with ECB mode or CBC mode continuing from previous cipher block
+func (d *decryptReader) decryptBlocks(in []byte) []byte {
+	blockSize := d.b.BlockSize()
+	out := make([]byte, len(in))
+	for from := 0; from < len(in); from += blockSize {
+		state := out[from : from+blockSize]
+		copy(state, in[from:from+blockSize])
+		d.b.invBlockCipher(state)
+		if d.previous == nil {
+			continue
+		}
+		// XOR with previous cipher block
+		for j := 0; j < blockSize; j++ {
+			state[j] ^= d.previous[j]
+		}
+		copy(d.previous, in[from:from+blockSize])
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package aes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Step names of TraceEvent
+const (
+	StepSubBytes      = "SubBytes"
+	StepShiftRows     = "ShiftRows"
+	StepMixColumns    = "MixColumns"
+	StepAddRoundKey   = "AddRoundKey"
+	StepInvSubBytes   = "InvSubBytes"
+	StepInvShiftRows  = "InvShiftRows"
+	StepInvMixColumns = "InvMixColumns"
+	// StepRound is a whole round computed at once by T-table implementation
+	StepRound = "Round"
+)
+
+// TraceEvent is a step of the round function applied to the state
+type TraceEvent struct {
+	Round    int
+	Step     string
+	Decrypt  bool
+	Before   []byte
+	After    []byte
+	RoundKey []byte // round key used in the step. It's nil except AddRoundKey and Round.
+}
+
+// Tracer receives trace events of the cipher.
+// Trace may be called from multiple goroutines if the Block is used concurrently.
+type Tracer interface {
+	Trace(event TraceEvent)
+}
+
+// SetTracer sets the tracer which receives trace events of b. nil disables tracing.
+// It must be called before b is used.
+func (b *Block) SetTracer(tracer Tracer) {
+	b.tracer = tracer
+}
+
+// traceStep applies transform to the state and sends the event to the tracer.
+// States are copied only when tracer is not nil.
+func traceStep(tracer Tracer, round int, step string, decrypt bool, state, key []byte, transform func()) {
+	if tracer == nil {
+		transform()
+		return
+	}
+
+	before := make([]byte, len(state))
+	copy(before, state)
+	transform()
+	after := make([]byte, len(state))
+	copy(after, state)
+
+	var roundKey []byte
+	if key != nil {
+		roundKey = make([]byte, len(key))
+		copy(roundKey, key)
+	}
+	tracer.Trace(TraceEvent{
+		Round:    round,
+		Step:     step,
+		Decrypt:  decrypt,
+		Before:   before,
+		After:    after,
+		RoundKey: roundKey,
+	})
+}
+
+// roundFilter passes only events of a round to the underlying tracer
+type roundFilter struct {
+	tracer Tracer
+	round  int
+}
+
+// NewRoundFilter returns Tracer which passes only events of given round to tracer
+func NewRoundFilter(tracer Tracer, round int) Tracer {
+	return &roundFilter{
+		tracer: tracer,
+		round:  round,
+	}
+}
+
+// Trace passes the event to the underlying tracer if the round matches
+func (f *roundFilter) Trace(event TraceEvent) {
+	if event.Round == f.round {
+		f.tracer.Trace(event)
+	}
+}
+
+// TextTracer writes trace events in human readable text.
+// Events after a write error are dropped and the error is reported by Err.
+type TextTracer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewTextTracer returns TextTracer which writes to w
+func NewTextTracer(w io.Writer) *TextTracer {
+	return &TextTracer{w: w}
+}
+
+// Trace writes the event
+func (t *TextTracer) Trace(event TraceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+
+	text := fmt.Sprintf("[Round %d] %s\n", event.Round, event.Step)
+	text += fmt.Sprintf("  Before: %s\n", PrintableBytes(event.Before))
+	if event.RoundKey != nil {
+		text += fmt.Sprintf("  Key:    %s\n", PrintableBytes(event.RoundKey))
+	}
+	text += fmt.Sprintf("  After:  %s\n", PrintableBytes(event.After))
+	_, t.err = io.WriteString(t.w, text)
+}
+
+// Err returns the first error occurred while writing events
+func (t *TextTracer) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// JSONTracer writes trace events in JSON Lines. Byte strings are hexadecimal encoded.
+// Events after an encoding error are dropped and the error is reported by Err.
+type JSONTracer struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// jsonTraceEvent is JSON representation of TraceEvent
+type jsonTraceEvent struct {
+	Round    int    `json:"round"`
+	Step     string `json:"step"`
+	Decrypt  bool   `json:"decrypt"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+	RoundKey string `json:"round_key,omitempty"`
+}
+
+// NewJSONTracer returns JSONTracer which writes to w
+func NewJSONTracer(w io.Writer) *JSONTracer {
+	return &JSONTracer{enc: json.NewEncoder(w)}
+}
+
+// Trace writes the event as a line of JSON
+func (t *JSONTracer) Trace(event TraceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+
+	t.err = t.enc.Encode(jsonTraceEvent{
+		Round:    event.Round,
+		Step:     event.Step,
+		Decrypt:  event.Decrypt,
+		Before:   hex.EncodeToString(event.Before),
+		After:    hex.EncodeToString(event.After),
+		RoundKey: hex.EncodeToString(event.RoundKey),
+	})
+}
+
+// Err returns the first error occurred while writing events
+func (t *JSONTracer) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// TraceRecorder records trace events in memory
+type TraceRecorder struct {
+	mu     sync.Mutex
+	events []TraceEvent
+}
+
+// Trace records the event
+func (r *TraceRecorder) Trace(event TraceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns recorded events
+func (r *TraceRecorder) Events() []TraceEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]TraceEvent, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// Reset discards recorded events
+func (r *TraceRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
@@ -0,0 +1,153 @@
+package aes
+
+import (
+	"encoding/binary"
+)
+
+var (
+	// te is the T-tables for encryption round. te[k][x] is the k-th column of polyMatrix multiplied by S(x).
+	te [BytesOfWords][256]uint32
+	// td is the T-tables for decryption round. td[k][x] is the k-th column of invPolyMatrix multiplied by InvS(x).
+	td [BytesOfWords][256]uint32
+)
+
+func init() {
+	generateTTables()
+}
+
+// generateTTables generates T-tables from sbox and polyMatrix
+func generateTTables() {
+	for k := 0; k < BytesOfWords; k++ {
+		for x := 0; x < 256; x++ {
+			var e, d uint32
+			for r := 0; r < BytesOfWords; r++ {
+				e |= uint32(Mul(sbox[x], polyMatrix[r][k])) << uint(24-8*r)
+				d |= uint32(Mul(invSbox[x], invPolyMatrix[r][k])) << uint(24-8*r)
+			}
+			te[k][x] = e
+			td[k][x] = d
+		}
+	}
+}
+
+// expandTTableKey converts expanded key and the decryption key schedule of the equivalent inverse cipher
+// into words for T-table implementation
+func (b *Block) expandTTableKey() {
+	n := b.nb * (b.nr + 1)
+	b.encKey = make([]uint32, n)
+	b.decKey = make([]uint32, n)
+
+	for i := 0; i < n; i++ {
+		b.encKey[i] = binary.BigEndian.Uint32(b.expandedKey[i*BytesOfWords:])
+		b.decKey[i] = binary.BigEndian.Uint32(b.eqInvKey[i*BytesOfWords:])
+	}
+}
+
+// tTableCipher encrypts given state with T-tables.
+// SubBytes, ShiftRows and MixColumns of each round are combined into four table lookups per column.
+func (b *Block) tTableCipher(state []byte) {
+	nb := b.nb
+	c1, c2, c3 := shiftOffset(1, nb), shiftOffset(2, nb), shiftOffset(3, nb)
+	tracer := b.tracer
+	s := make([]uint32, nb)
+	t := make([]uint32, nb)
+	for c := 0; c < nb; c++ {
+		t[c] = binary.BigEndian.Uint32(state[c*BytesOfWords:])
+		s[c] = t[c] ^ b.encKey[c]
+	}
+	traceTTableRound(tracer, 0, StepAddRoundKey, false, t, s, b.encKey[:nb])
+
+	for round := 1; round < b.nr; round++ {
+		key := b.encKey[round*nb : (round+1)*nb]
+		for c := 0; c < nb; c++ {
+			t[c] = te[0][s[c]>>24] ^
+				te[1][s[(c+c1)%nb]>>16&0xff] ^
+				te[2][s[(c+c2)%nb]>>8&0xff] ^
+				te[3][s[(c+c3)%nb]&0xff] ^
+				key[c]
+		}
+		traceTTableRound(tracer, round, StepRound, false, s, t, key)
+		s, t = t, s
+	}
+
+	// the last round doesn't have MixColumns
+	key := b.encKey[b.nr*nb : (b.nr+1)*nb]
+	for c := 0; c < nb; c++ {
+		t[c] = (uint32(sbox[s[c]>>24])<<24 |
+			uint32(sbox[s[(c+c1)%nb]>>16&0xff])<<16 |
+			uint32(sbox[s[(c+c2)%nb]>>8&0xff])<<8 |
+			uint32(sbox[s[(c+c3)%nb]&0xff])) ^ key[c]
+	}
+	traceTTableRound(tracer, b.nr, StepRound, false, s, t, key)
+
+	for c := 0; c < nb; c++ {
+		binary.BigEndian.PutUint32(state[c*BytesOfWords:], t[c])
+	}
+}
+
+// tTableInvCipher decrypts given state with T-tables using the equivalent inverse cipher
+func (b *Block) tTableInvCipher(state []byte) {
+	nb := b.nb
+	c1, c2, c3 := shiftOffset(1, nb), shiftOffset(2, nb), shiftOffset(3, nb)
+	tracer := b.tracer
+	s := make([]uint32, nb)
+	t := make([]uint32, nb)
+	key := b.decKey[b.nr*nb : (b.nr+1)*nb]
+	for c := 0; c < nb; c++ {
+		t[c] = binary.BigEndian.Uint32(state[c*BytesOfWords:])
+		s[c] = t[c] ^ key[c]
+	}
+	traceTTableRound(tracer, b.nr, StepAddRoundKey, true, t, s, key)
+
+	for round := b.nr - 1; round > 0; round-- {
+		key := b.decKey[round*nb : (round+1)*nb]
+		for c := 0; c < nb; c++ {
+			t[c] = td[0][s[c]>>24] ^
+				td[1][s[(c+nb-c1)%nb]>>16&0xff] ^
+				td[2][s[(c+nb-c2)%nb]>>8&0xff] ^
+				td[3][s[(c+nb-c3)%nb]&0xff] ^
+				key[c]
+		}
+		traceTTableRound(tracer, round, StepRound, true, s, t, key)
+		s, t = t, s
+	}
+
+	// the last round doesn't have InvMixColumns
+	key = b.decKey[:nb]
+	for c := 0; c < nb; c++ {
+		t[c] = (uint32(invSbox[s[c]>>24])<<24 |
+			uint32(invSbox[s[(c+nb-c1)%nb]>>16&0xff])<<16 |
+			uint32(invSbox[s[(c+nb-c2)%nb]>>8&0xff])<<8 |
+			uint32(invSbox[s[(c+nb-c3)%nb]&0xff])) ^ key[c]
+	}
+	traceTTableRound(tracer, 0, StepRound, true, s, t, key)
+
+	for c := 0; c < nb; c++ {
+		binary.BigEndian.PutUint32(state[c*BytesOfWords:], t[c])
+	}
+}
+
+// traceTTableRound sends the event of a round to the tracer.
+// T-table implementation computes a whole round at once, so intermediate steps are not traced.
+func traceTTableRound(tracer Tracer, round int, step string, decrypt bool, before, after, key []uint32) {
+	if tracer == nil {
+		return
+	}
+	tracer.Trace(TraceEvent{
+		Round:    round,
+		Step:     step,
+		Decrypt:  decrypt,
+		Before:   wordsToBytes(before),
+		After:    wordsToBytes(after),
+		RoundKey: wordsToBytes(key),
+	})
+}
+
+// wordsToBytes converts big-endian words into bytes
+func wordsToBytes(words []uint32) []byte {
+	out := make([]byte, len(words)*BytesOfWords)
+	for c := range words {
+		binary.BigEndian.PutUint32(out[c*BytesOfWords:], words[c])
+	}
+	return out
+}
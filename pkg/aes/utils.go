@@ -4,27 +4,49 @@ import (
 	"fmt"
 )
 
-// Xtime calculate multiplis n and 2 in a Galois Field
+// Xtime calculate multiplis n and 2 in a Galois Field.
+// It doesn't branch on n to run in constant time.
 func Xtime(n byte) byte {
-	p := int(n) << 1
-	if p&0x100 != 0 {
-		p ^= poly
+	return xtime(n, poly)
+}
+
+// xtime multiplies n and 2 in GF(2^8) defined by polynomial p without branch
+func xtime(n byte, p int) byte {
+	// mask is 0xff if the most significant bit of n is set, otherwise 0x00
+	mask := byte(int8(n) >> 7)
+	return n<<1 ^ mask&byte(p)
+}
+
+// Xtime128 calculate multiplis n and 2 in GF(2^128) defined by x¹²⁸ + x⁷ + x² + x + 1.
+// n is 128 bit big-endian value as used in CMAC and S2V.
+func Xtime128(n []byte) []byte {
+	p := make([]byte, len(n))
+	for i := 0; i < len(n)-1; i++ {
+		p[i] = n[i]<<1 | n[i+1]>>7
+	}
+	p[len(n)-1] = n[len(n)-1] << 1
+	if n[0]&0x80 != 0 {
+		p[len(n)-1] ^= 0x87
 	}
-	return byte(p)
+	return p
 }
 
-// Mul multiplies n and p in a Galois Field
+// Mul multiplies n and p in a Galois Field.
+// It always iterates over all 8 bits of p without branch to run in constant time.
 func Mul(n, p byte) byte {
-	switch {
-	case p == 0:
-		return 0
-	case p%2 == 0:
-		t := Mul(n, p/2)
-		return Xtime(t)
-	case p%2 == 1:
-		return n ^ Mul(n, p-1)
+	return mul(n, p, poly)
+}
+
+// mul multiplies n and m in GF(2^8) defined by polynomial p without branch
+func mul(n, m byte, p int) byte {
+	var r byte
+	for i := 0; i < 8; i++ {
+		// -(m & 1) is 0xff if the lowest bit of m is set, otherwise 0x00
+		r ^= n & -(m & 1)
+		n = xtime(n, p)
+		m >>= 1
 	}
-	return n
+	return r
 }
 
 // PrintableBytes returns printable string from []byte
@@ -38,9 +60,3 @@ func PrintableBytes(bytes []byte) (str string) {
 	}
 	return
 }
-
-func printRoundBytes(bytes []byte, round int, phase string) {
-	if round == PrintNRound {
-		fmt.Printf("After %s: %s\n", phase, PrintableBytes(bytes))
-	}
-}
@@ -0,0 +1,191 @@
+package aes
+
+import (
+	"encoding/binary"
+)
+
+// xtsBlockSize is the block size (byte) of XTS. It's fixed to 128 bit.
+const xtsBlockSize = 16
+
+// XTS is an XTS-AES instance defined in IEEE 1619 and NIST SP 800-38E.
+// It holds two AES keys: Key1 for data and Key2 for tweak.
+type XTS struct {
+	k1 *Block
+	k2 *Block
+}
+
+// NewXTS creates XTS from given double-length key.
+// The key must be 32 or 64 bytes to select XTS-AES-128 or XTS-AES-256.
+func NewXTS(key []byte) (*XTS, error) {
+	if len(key) != 32 && len(key) != 64 {
+		return nil, ErrKeySize
+	}
+
+	k1, err := NewCipher(key[:len(key)/2])
+	if err != nil {
+		return nil, err
+	}
+	k2, err := NewCipher(key[len(key)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return &XTS{
+		k1: k1,
+		k2: k2,
+	}, nil
+}
+
+// SetTracer sets the tracer which receives trace events of both the data key and the tweak key.
+// It must be called before x is used.
+func (x *XTS) SetTracer(tracer Tracer) {
+	x.k1.SetTracer(tracer)
+	x.k2.SetTracer(tracer)
+}
+
+// SectorTweak returns 128 bit tweak from given sector number (data unit sequence number).
+// The sector number is encoded in little-endian as described in IEEE 1619.
+func SectorTweak(sector uint64) []byte {
+	tweak := make([]byte, xtsBlockSize)
+	binary.LittleEndian.PutUint64(tweak, sector)
+	return tweak
+}
+
+// Encrypt encrypts given data unit with 128 bit tweak.
+// Data unit must be at least one block; a partial last block is handled with ciphertext stealing.
+func (x *XTS) Encrypt(in, tweak []byte) ([]byte, error) {
+	if len(tweak) != xtsBlockSize {
+		return nil, ErrIVSize
+	}
+	if len(in) < xtsBlockSize {
+		return nil, ErrPlaintextLength
+	}
+
+	out := make([]byte, len(in))
+	t := make([]byte, xtsBlockSize)
+	copy(t, tweak)
+	x.k2.blockCipher(t)
+
+	numOfFullBlocks := len(in) / xtsBlockSize
+	lastBlockLength := len(in) % xtsBlockSize
+	for i := 0; i < numOfFullBlocks; i++ {
+		from := i * xtsBlockSize
+		to := (i + 1) * xtsBlockSize
+		copy(out[from:to], x.encryptBlock(in[from:to], t))
+		mulAlpha(t)
+	}
+
+	if lastBlockLength != 0 {
+		// steal tail bytes of previous cipher block as padding like CBC-CTS
+		from := numOfFullBlocks * xtsBlockSize
+		state := make([]byte, xtsBlockSize)
+		copy(state, in[from:])
+		copy(state[lastBlockLength:], out[from-xtsBlockSize+lastBlockLength:from])
+
+		copy(out[from:], out[from-xtsBlockSize:from-xtsBlockSize+lastBlockLength])
+		copy(out[from-xtsBlockSize:from], x.encryptBlock(state, t))
+	}
+	return out, nil
+}
+
+// Decrypt decrypts given data unit with 128 bit tweak
+func (x *XTS) Decrypt(in, tweak []byte) ([]byte, error) {
+	if len(tweak) != xtsBlockSize {
+		return nil, ErrIVSize
+	}
+	if len(in) < xtsBlockSize {
+		return nil, ErrCiphertextLength
+	}
+
+	out := make([]byte, len(in))
+	t := make([]byte, xtsBlockSize)
+	copy(t, tweak)
+	x.k2.blockCipher(t)
+
+	numOfFullBlocks := len(in) / xtsBlockSize
+	lastBlockLength := len(in) % xtsBlockSize
+	if lastBlockLength != 0 {
+		// the last full block is processed with ciphertext stealing below
+		numOfFullBlocks--
+	}
+	for i := 0; i < numOfFullBlocks; i++ {
+		from := i * xtsBlockSize
+		to := (i + 1) * xtsBlockSize
+		copy(out[from:to], x.decryptBlock(in[from:to], t))
+		mulAlpha(t)
+	}
+
+	if lastBlockLength != 0 {
+		from := numOfFullBlocks * xtsBlockSize
+		// the last full cipher block was encrypted with the next tweak
+		nextT := make([]byte, xtsBlockSize)
+		copy(nextT, t)
+		mulAlpha(nextT)
+		pp := x.decryptBlock(in[from:from+xtsBlockSize], nextT)
+
+		state := make([]byte, xtsBlockSize)
+		copy(state, in[from+xtsBlockSize:])
+		copy(state[lastBlockLength:], pp[lastBlockLength:])
+
+		copy(out[from+xtsBlockSize:], pp[:lastBlockLength])
+		copy(out[from:from+xtsBlockSize], x.decryptBlock(state, t))
+	}
+	return out, nil
+}
+
+// XTSCipher encrypts given data unit with XTS mode.
+// The key must be double-length and iv is used as 128 bit tweak.
+func XTSCipher(in, key, iv []byte) ([]byte, error) {
+	x, err := NewXTS(key)
+	if err != nil {
+		return nil, err
+	}
+	return x.Encrypt(in, iv)
+}
+
+// XTSInvCipher decrypts given data unit with XTS mode
+func XTSInvCipher(in, key, iv []byte) ([]byte, error) {
+	x, err := NewXTS(key)
+	if err != nil {
+		return nil, err
+	}
+	return x.Decrypt(in, iv)
+}
+
+// encryptBlock calculates CC = E_K1(P xor T) xor T
+func (x *XTS) encryptBlock(in, t []byte) []byte {
+	state := make([]byte, xtsBlockSize)
+	for j := 0; j < xtsBlockSize; j++ {
+		state[j] = in[j] ^ t[j]
+	}
+	x.k1.blockCipher(state)
+	for j := 0; j < xtsBlockSize; j++ {
+		state[j] ^= t[j]
+	}
+	return state
+}
+
+// decryptBlock calculates PP = D_K1(C xor T) xor T
+func (x *XTS) decryptBlock(in, t []byte) []byte {
+	state := make([]byte, xtsBlockSize)
+	for j := 0; j < xtsBlockSize; j++ {
+		state[j] = in[j] ^ t[j]
+	}
+	x.k1.invBlockCipher(state)
+	for j := 0; j < xtsBlockSize; j++ {
+		state[j] ^= t[j]
+	}
+	return state
+}
+
+// mulAlpha multiplies given tweak and primitive element α in GF(2^128).
+// Tweak is treated as little-endian as described in IEEE 1619.
+func mulAlpha(t []byte) {
+	carry := t[xtsBlockSize-1] >> 7
+	for j := xtsBlockSize - 1; j > 0; j-- {
+		t[j] = t[j]<<1 | t[j-1]>>7
+	}
+	t[0] <<= 1
+	if carry == 1 {
+		t[0] ^= 0x87
+	}
+}
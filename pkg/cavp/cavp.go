@@ -0,0 +1,213 @@
+// Package cavp parses NIST CAVP (Cryptographic Algorithm Validation Program) response files (.rsp)
+// of the AES Algorithm Validation Suite (AESAVS) and runs them against pkg/aes.
+//
+// A file name is <MODE><TEST><KEY BITS>.rsp, e.g. ECBGFSbox128.rsp, CBCMMT192.rsp or CFB1MCT256.rsp.
+// Known answer tests (GFSbox, KeySbox, VarKey, VarTxt) and multi-block message tests (MMT) are checked
+// vector by vector and Monte Carlo tests (MCT) follow the algorithm of AESAVS Section 6.4.
+package cavp
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Mode is the block cipher mode of the test file
+type Mode string
+
+const (
+	// ModeECB is ECB mode
+	ModeECB Mode = "ECB"
+	// ModeCBC is CBC mode
+	ModeCBC Mode = "CBC"
+	// ModeCFB1 is CFB mode with 1 bit segment
+	ModeCFB1 Mode = "CFB1"
+	// ModeCFB8 is CFB mode with 8 bit segment
+	ModeCFB8 Mode = "CFB8"
+	// ModeCFB128 is CFB mode with 128 bit segment
+	ModeCFB128 Mode = "CFB128"
+	// ModeOFB is OFB mode
+	ModeOFB Mode = "OFB"
+	// ModeCTR is CTR mode. IV is the initial counter block.
+	ModeCTR Mode = "CTR"
+)
+
+// Test is the type of the test file
+type Test string
+
+const (
+	// TestGFSbox is the known answer test of AESAVS Appendix B
+	TestGFSbox Test = "GFSbox"
+	// TestKeySbox is the known answer test of AESAVS Appendix C
+	TestKeySbox Test = "KeySbox"
+	// TestVarTxt is the known answer test of AESAVS Appendix D
+	TestVarTxt Test = "VarTxt"
+	// TestVarKey is the known answer test of AESAVS Appendix E
+	TestVarKey Test = "VarKey"
+	// TestMMT is the multi-block message test
+	TestMMT Test = "MMT"
+	// TestMCT is the Monte Carlo test
+	TestMCT Test = "MCT"
+)
+
+var (
+	// ErrFileName is returned when the file name isn't <MODE><TEST><KEY BITS>.rsp
+	ErrFileName = errors.New("invalid CAVP file name")
+	// ErrSyntax is returned when the file has a malformed line
+	ErrSyntax = errors.New("invalid CAVP file syntax")
+)
+
+// modes is the list of modes. CFB128 must be checked before CFB1.
+var modes = []Mode{ModeECB, ModeCBC, ModeCFB128, ModeCFB8, ModeCFB1, ModeOFB, ModeCTR}
+
+// tests is the list of tests
+var tests = []Test{TestGFSbox, TestKeySbox, TestVarTxt, TestVarKey, TestMMT, TestMCT}
+
+// Vector is a test case of the file.
+// In CFB1 files, every element of PlainText and CipherText is a bit (0 or 1).
+type Vector struct {
+	Count      int
+	Decrypt    bool
+	Key        []byte
+	IV         []byte
+	PlainText  []byte
+	CipherText []byte
+}
+
+// File is a parsed response file
+type File struct {
+	Name    string
+	Mode    Mode
+	Test    Test
+	KeyBits int
+	Vectors []Vector
+}
+
+// ParseFileName returns the mode, the test and the key length (bit) from the file name
+func ParseFileName(name string) (Mode, Test, int, error) {
+	base := strings.TrimSuffix(filepath.Base(name), ".rsp")
+	for _, m := range modes {
+		if !strings.HasPrefix(base, string(m)) {
+			continue
+		}
+		for _, t := range tests {
+			rest := strings.TrimPrefix(base, string(m))
+			if !strings.HasPrefix(rest, string(t)) {
+				continue
+			}
+			switch bits := strings.TrimPrefix(rest, string(t)); bits {
+			case "128", "192", "256":
+				keyBits, _ := strconv.Atoi(bits)
+				return m, t, keyBits, nil
+			}
+		}
+	}
+	return "", "", 0, ErrFileName
+}
+
+// Parse reads the response file of given name.
+// Lines starting with # are comments, and [ENCRYPT] and [DECRYPT] begin the vectors of each direction.
+func Parse(name string, r io.Reader) (*File, error) {
+	mode, test, keyBits, err := ParseFileName(name)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		Name:    filepath.Base(name),
+		Mode:    mode,
+		Test:    test,
+		KeyBits: keyBits,
+	}
+
+	decrypt := false
+	var v *Vector
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+			continue
+		case text == "[ENCRYPT]" || text == "[DECRYPT]":
+			decrypt = text == "[DECRYPT]"
+			continue
+		}
+
+		fields := strings.SplitN(text, "=", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: %w", f.Name, line, ErrSyntax)
+		}
+		key := strings.TrimSpace(fields[0])
+		value := strings.TrimSpace(fields[1])
+		if key == "COUNT" {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", f.Name, line, ErrSyntax)
+			}
+			f.Vectors = append(f.Vectors, Vector{
+				Count:   count,
+				Decrypt: decrypt,
+			})
+			v = &f.Vectors[len(f.Vectors)-1]
+			continue
+		}
+		if v == nil {
+			return nil, fmt.Errorf("%s:%d: %w", f.Name, line, ErrSyntax)
+		}
+
+		var dst *[]byte
+		switch key {
+		case "KEY":
+			dst = &v.Key
+		case "IV":
+			dst = &v.IV
+		case "PLAINTEXT":
+			dst = &v.PlainText
+		case "CIPHERTEXT":
+			dst = &v.CipherText
+		default:
+			return nil, fmt.Errorf("%s:%d: %w", f.Name, line, ErrSyntax)
+		}
+		if mode == ModeCFB1 && (key == "PLAINTEXT" || key == "CIPHERTEXT") {
+			*dst, err = decodeBits(value)
+		} else {
+			*dst, err = hex.DecodeString(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", f.Name, line, ErrSyntax)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ParseFile reads the response file at given path
+func ParseFile(path string) (*File, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return Parse(path, r)
+}
+
+// decodeBits decodes string of 0 and 1 into bits
+func decodeBits(s string) ([]byte, error) {
+	bits := make([]byte, len(s))
+	for i, c := range s {
+		switch c {
+		case '0', '1':
+			bits[i] = byte(c - '0')
+		default:
+			return nil, ErrSyntax
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,271 @@
+package cavp
+
+import (
+	"bytes"
+	stdaes "crypto/aes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+	"github.com/mas9612/cryptostudy/pkg/util"
+)
+
+const testdata = "testdata"
+
+func TestParseFileName(t *testing.T) {
+	cases := []struct {
+		name    string
+		mode    Mode
+		test    Test
+		keyBits int
+	}{
+		{"ECBGFSbox128.rsp", ModeECB, TestGFSbox, 128},
+		{"testdata/CBCKeySbox192.rsp", ModeCBC, TestKeySbox, 192},
+		{"CFB1VarTxt256.rsp", ModeCFB1, TestVarTxt, 256},
+		{"CFB128VarKey128.rsp", ModeCFB128, TestVarKey, 128},
+		{"CFB8MMT192.rsp", ModeCFB8, TestMMT, 192},
+		{"OFBMCT256.rsp", ModeOFB, TestMCT, 256},
+		{"CTRMMT128.rsp", ModeCTR, TestMMT, 128},
+	}
+	for i, c := range cases {
+		mode, test, keyBits, err := ParseFileName(c.name)
+		if err != nil {
+			t.Fatalf("[TestParseFileName] case %d failed: %v", i, err)
+		}
+		if mode != c.mode || test != c.test || keyBits != c.keyBits {
+			t.Errorf("[TestParseFileName] case %d failed: result '%s %s %d', but expected '%s %s %d'", i, mode, test, keyBits, c.mode, c.test, c.keyBits)
+		}
+	}
+
+	for i, name := range []string{"ECBGFSbox.rsp", "ECBGFSbox64.rsp", "XTSMMT128.rsp", "CFB16MMT128.rsp", "CBCFoo128.rsp"} {
+		if _, _, _, err := ParseFileName(name); !errors.Is(err, ErrFileName) {
+			t.Errorf("[TestParseFileName] invalid case %d failed: err '%v', but expected '%v'", i, err, ErrFileName)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	rsp := "# CAVS 11.1\r\n" +
+		"# Config info for aes_values\r\n" +
+		"\r\n" +
+		"[ENCRYPT]\r\n" +
+		"\r\n" +
+		"COUNT = 0\r\n" +
+		"KEY = 00000000000000000000000000000000\r\n" +
+		"IV = f34481ec3cc627bacd5dc3fb08f273e6\r\n" +
+		"PLAINTEXT = 0\r\n" +
+		"CIPHERTEXT = 0\r\n" +
+		"\r\n" +
+		"[DECRYPT]\r\n" +
+		"\r\n" +
+		"COUNT = 0\r\n" +
+		"KEY = 00000000000000000000000000000000\r\n" +
+		"IV = 9798c4640bad75c7c3227db910174e72\r\n" +
+		"CIPHERTEXT = 101\r\n" +
+		"PLAINTEXT = 011\r\n"
+
+	f, err := Parse("CFB1GFSbox128.rsp", strings.NewReader(rsp))
+	if err != nil {
+		t.Fatalf("[TestParse] failed: %v", err)
+	}
+	if f.Mode != ModeCFB1 || f.Test != TestGFSbox || f.KeyBits != 128 || len(f.Vectors) != 2 {
+		t.Fatalf("[TestParse] failed: result '%s %s %d' with %d vectors", f.Mode, f.Test, f.KeyBits, len(f.Vectors))
+	}
+	v := f.Vectors[1]
+	iv := []byte{0x97, 0x98, 0xc4, 0x64, 0x0b, 0xad, 0x75, 0xc7, 0xc3, 0x22, 0x7d, 0xb9, 0x10, 0x17, 0x4e, 0x72}
+	if !v.Decrypt || v.Count != 0 || !bytes.Equal(v.IV, iv) || len(v.Key) != 16 {
+		t.Errorf("[TestParse] failed: vector %+v", v)
+	}
+	if !bytes.Equal(v.CipherText, []byte{1, 0, 1}) || !bytes.Equal(v.PlainText, []byte{0, 1, 1}) {
+		t.Errorf("[TestParse] failed: bits '%v' and '%v', but expected '[1 0 1]' and '[0 1 1]'", v.CipherText, v.PlainText)
+	}
+
+	invalids := []struct {
+		name string
+		rsp  string
+	}{
+		{"ECBMMT128.rsp", "KEY = 00\n"},
+		{"ECBMMT128.rsp", "COUNT = 0\nKEY\n"},
+		{"ECBMMT128.rsp", "COUNT = zero\n"},
+		{"ECBMMT128.rsp", "COUNT = 0\nKEY = 0g\n"},
+		{"ECBMMT128.rsp", "COUNT = 0\nNONCE = 00\n"},
+		{"CFB1MMT128.rsp", "COUNT = 0\nPLAINTEXT = 012\n"},
+	}
+	for i, c := range invalids {
+		if _, err := Parse(c.name, strings.NewReader(c.rsp)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("[TestParse] invalid case %d failed: err '%v', but expected '%v'", i, err, ErrSyntax)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var names []string
+	for _, dir := range []string{testdata, filepath.Join(testdata, "sp800-38a")} {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("[TestRun] failed: %v", err)
+		}
+		for _, info := range infos {
+			if strings.HasSuffix(info.Name(), ".rsp") {
+				names = append(names, filepath.Join(dir, info.Name()))
+			}
+		}
+	}
+	implementations := []aes.Implementation{aes.ImplementationReference, aes.ImplementationTTable, aes.ImplementationBitsliced}
+
+	for _, name := range names {
+		f, err := ParseFile(name)
+		if err != nil {
+			t.Fatalf("[TestRun] %s failed: %v", name, err)
+		}
+		if len(f.Vectors) == 0 {
+			t.Errorf("[TestRun] %s failed: no vectors", f.Name)
+		}
+
+		impls := implementations
+		if f.Test == TestMCT {
+			if testing.Short() {
+				continue
+			}
+			// Monte Carlo test runs 100,000 blocks for each direction
+			impls = []aes.Implementation{aes.ImplementationTTable}
+		}
+		for _, impl := range impls {
+			result, err := Run(f, impl)
+			if err != nil {
+				t.Fatalf("[TestRun] %s implementation %d failed: %v", f.Name, impl, err)
+			}
+			for _, failure := range result.Failures {
+				t.Errorf("[TestRun] %s implementation %d COUNT = %d (decrypt %v) failed: %s '%x', but expected '%x'", f.Name, impl, failure.Count, failure.Decrypt, failure.Field, failure.Output, failure.Expected)
+			}
+			if result.Passed != len(f.Vectors) {
+				t.Errorf("[TestRun] %s implementation %d failed: %d vectors passed, but expected %d", f.Name, impl, result.Passed, len(f.Vectors))
+			}
+		}
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	f, err := ParseFile(filepath.Join(testdata, "CBCGFSbox128.rsp"))
+	if err != nil {
+		t.Fatalf("[TestRunFailure] failed: %v", err)
+	}
+	f.Vectors[2].CipherText[0] ^= 1
+	result, err := Run(f, aes.ImplementationReference)
+	if err != nil {
+		t.Fatalf("[TestRunFailure] failed: %v", err)
+	}
+	if result.OK() || result.Failed != 1 || result.Failures[0].Count != 2 || result.Failures[0].Field != "CIPHERTEXT" {
+		t.Errorf("[TestRunFailure] failed: result %+v", result)
+	}
+
+	// a wrong key fails only the vector in the chain, and RunReseeded also reports the previous vector which derives it
+	f = &File{Name: "ECBMCT128.rsp", Mode: ModeECB, Test: TestMCT, KeyBits: 128}
+	f.Vectors = ecbMonteCarlo(t, make([]byte, 16), make([]byte, 16), 10)
+	f.Vectors[5].Key[0] ^= 1
+	result, err = Run(f, aes.ImplementationTTable)
+	if err != nil {
+		t.Fatalf("[TestRunFailure] failed: %v", err)
+	}
+	if result.Failed != 1 || result.Passed != 9 || result.Failures[0].Count != 5 || result.Failures[0].Field != "KEY" {
+		t.Errorf("[TestRunFailure] failed: result %+v", result)
+	}
+	result, err = RunReseeded(f, aes.ImplementationTTable)
+	if err != nil {
+		t.Fatalf("[TestRunFailure] failed: %v", err)
+	}
+	if result.Failed != 2 || result.Failures[0].Count != 4 || result.Failures[0].Field != "next KEY" || result.Failures[1].Count != 5 {
+		t.Errorf("[TestRunFailure] failed: result %+v", result)
+	}
+}
+
+func TestMonteCarlo(t *testing.T) {
+	// COUNT = 0 of ENCRYPT in ECBMCT128.rsp, ECBMCT256.rsp and CBCMCT128.rsp of NIST's aesmct.zip
+	cases := []struct {
+		name string
+		mode Mode
+		v    Vector
+	}{
+		{"ECBMCT128.rsp", ModeECB, Vector{
+			Key:        util.HexStringToBytes("139a35422f1d61de3c91787fe0507afd"),
+			PlainText:  util.HexStringToBytes("b9145a768b7dc489a096b546f43b231f"),
+			CipherText: util.HexStringToBytes("d7c3ffac9031238650901e157364c386"),
+		}},
+		{"ECBMCT256.rsp", ModeECB, Vector{
+			Key:        util.HexStringToBytes("f9e8389f5b80712e3886cc1fa2d28a3b8c9cd88a2d4a54c6aa86ce0fef944be0"),
+			PlainText:  util.HexStringToBytes("b379777f9050e2a818f2940cbbd9aba4"),
+			CipherText: util.HexStringToBytes("6893ebaf0a1fccc704326529fdfb60db"),
+		}},
+		{"CBCMCT128.rsp", ModeCBC, Vector{
+			Key:        util.HexStringToBytes("8809e7dd3a959ee5d8dbb13f501f2274"),
+			IV:         util.HexStringToBytes("e5c0bb535d7d54572ad06d170a0e58ae"),
+			PlainText:  util.HexStringToBytes("1fd4ee65603e6130cfc2a82ab3d56c24"),
+			CipherText: util.HexStringToBytes("b127a5b4c4692d87483db0c3b0d11e64"),
+		}},
+	}
+	for i, c := range cases {
+		f := &File{Name: c.name, Mode: c.mode, Test: TestMCT, KeyBits: len(c.v.Key) * 8, Vectors: []Vector{c.v}}
+		result, err := Run(f, aes.ImplementationTTable)
+		if err != nil {
+			t.Fatalf("[TestMonteCarlo] case %d failed: %v", i, err)
+		}
+		if !result.OK() {
+			t.Errorf("[TestMonteCarlo] case %d failed: result %+v", i, result)
+		}
+	}
+
+	// the chain derives the inputs of every outer iteration as crypto/aes
+	for _, keyBytes := range []int{16, 24, 32} {
+		f := &File{Name: "ECBMCT.rsp", Mode: ModeECB, Test: TestMCT, KeyBits: keyBytes * 8}
+		f.Vectors = ecbMonteCarlo(t, make([]byte, keyBytes), make([]byte, 16), 5)
+		result, err := Run(f, aes.ImplementationReference)
+		if err != nil {
+			t.Fatalf("[TestMonteCarlo] %d bits key failed: %v", keyBytes*8, err)
+		}
+		if !result.OK() || result.Passed != len(f.Vectors) {
+			t.Errorf("[TestMonteCarlo] %d bits key failed: result %+v", keyBytes*8, result)
+		}
+	}
+}
+
+// ecbMonteCarlo returns n vectors of ECB Monte Carlo test computed with crypto/aes
+func ecbMonteCarlo(t *testing.T, key, plainText []byte, n int) []Vector {
+	vectors := make([]Vector, n)
+	for i := range vectors {
+		b, err := stdaes.NewCipher(key)
+		if err != nil {
+			t.Fatalf("[ecbMonteCarlo] failed: %v", err)
+		}
+		// the last two cipher texts, which are enough for the key of 256 bits
+		var stream [2 * stdaes.BlockSize]byte
+		copy(stream[stdaes.BlockSize:], plainText)
+		for j := 0; j < mctInnerLoops; j++ {
+			copy(stream[:stdaes.BlockSize], stream[stdaes.BlockSize:])
+			b.Encrypt(stream[stdaes.BlockSize:], stream[stdaes.BlockSize:])
+		}
+		cipherText := append([]byte{}, stream[stdaes.BlockSize:]...)
+		vectors[i] = Vector{Count: i, Key: key, PlainText: plainText, CipherText: cipherText}
+
+		nextKey := make([]byte, len(key))
+		tail := stream[len(stream)-len(key):]
+		for j := range nextKey {
+			nextKey[j] = key[j] ^ tail[j]
+		}
+		key, plainText = nextKey, cipherText
+	}
+	return vectors
+}
+
+func TestBits(t *testing.T) {
+	bits := []byte{1, 0, 1, 1, 0, 0, 0, 0, 1, 1}
+	packed := packBits(bits)
+	if !bytes.Equal(packed, []byte{0xb0, 0xc0}) {
+		t.Errorf("[TestBits] failed: packed '%x', but expected 'b0c0'", packed)
+	}
+	if unpacked := unpackBits(packed)[:len(bits)]; !bytes.Equal(unpacked, bits) {
+		t.Errorf("[TestBits] failed: unpacked '%v', but expected '%v'", unpacked, bits)
+	}
+}
@@ -0,0 +1,222 @@
+package cavp
+
+import (
+	"bytes"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+// mctInput is the inputs of an outer iteration of Monte Carlo test
+type mctInput struct {
+	key  []byte
+	iv   []byte
+	text []byte
+}
+
+// runMonteCarlo runs Monte Carlo test of AESAVS Section 6.4.
+// The outer iterations of each direction are one chain which starts from the inputs of the first vector (COUNT = 0).
+// Each vector passes if the inputs derived by the previous iteration and the output of its iteration match the file.
+func runMonteCarlo(f *File, impl aes.Implementation, result *Result) error {
+	var input mctInput
+	for i, v := range f.Vectors {
+		inputOK := true
+		if i == 0 || v.Decrypt != f.Vectors[i-1].Decrypt {
+			input = vectorInput(v)
+		} else if field, derived, inputs := mismatchedInput(input, v); field != "" {
+			// keep following the chain from the derived inputs
+			result.fail(v, field, derived, inputs)
+			inputOK = false
+		}
+
+		out, next, err := monteCarlo(f.Mode, impl, input, v.Decrypt)
+		if err != nil {
+			return err
+		}
+		input = next
+		if !inputOK {
+			continue
+		}
+		if expected := expectedOutput(v); !bytes.Equal(out, expected) {
+			result.fail(v, outputField(v), out, expected)
+			continue
+		}
+		result.Passed++
+	}
+	return nil
+}
+
+// runMonteCarloReseeded runs each outer iteration of Monte Carlo test from the inputs of the vector.
+// The vector passes if the output and the inputs derived for the next vector match the file.
+func runMonteCarloReseeded(f *File, impl aes.Implementation, result *Result) error {
+	for i, v := range f.Vectors {
+		out, next, err := monteCarlo(f.Mode, impl, vectorInput(v), v.Decrypt)
+		if err != nil {
+			return err
+		}
+		if expected := expectedOutput(v); !bytes.Equal(out, expected) {
+			result.fail(v, outputField(v), out, expected)
+			continue
+		}
+		// the last vector of each direction has no next inputs to compare
+		if i+1 < len(f.Vectors) && f.Vectors[i+1].Decrypt == v.Decrypt {
+			if field, derived, inputs := mismatchedInput(next, f.Vectors[i+1]); field != "" {
+				result.fail(v, "next "+field, derived, inputs)
+				continue
+			}
+		}
+		result.Passed++
+	}
+	return nil
+}
+
+// vectorInput returns the inputs of the vector
+func vectorInput(v Vector) mctInput {
+	input := mctInput{
+		key:  v.Key,
+		iv:   v.IV,
+		text: v.PlainText,
+	}
+	if v.Decrypt {
+		input.text = v.CipherText
+	}
+	return input
+}
+
+// expectedOutput returns the output of the vector
+func expectedOutput(v Vector) []byte {
+	if v.Decrypt {
+		return v.PlainText
+	}
+	return v.CipherText
+}
+
+// mismatchedInput returns the name and the values of the first derived input which differs from the vector.
+// The name is empty if every input matches.
+func mismatchedInput(next mctInput, v Vector) (string, []byte, []byte) {
+	field, text := "PLAINTEXT", v.PlainText
+	if v.Decrypt {
+		field, text = "CIPHERTEXT", v.CipherText
+	}
+	switch {
+	case !bytes.Equal(next.key, v.Key):
+		return "KEY", next.key, v.Key
+	case !bytes.Equal(next.iv, v.IV):
+		return "IV", next.iv, v.IV
+	case !bytes.Equal(next.text, text):
+		return field, next.text, text
+	}
+	return "", nil, nil
+}
+
+// monteCarlo runs an outer iteration of Monte Carlo test.
+// It returns the output of the last inner iteration and the inputs of the next outer iteration.
+//
+//	For j = 0 to 999
+//	    CT[j] = AES(Key[i], PT[j]) continuing the chain from IV[i]
+//	    PT[j+1] = CT[j]                            (ECB)
+//	    PT[j+1] = j-th segment of IV[i] or CT[j-n] (the others. n is the number of segments in a block)
+//	Key[i+1] = Key[i] XOR (the last bits of CT stream as long as the key)
+//	IV[i+1] = the last 128 bits of CT stream
+//	PT[0] = CT[999] (ECB) or CT[999-n] (the others)
+//
+// Decryption swaps PT and CT.
+func monteCarlo(mode Mode, impl aes.Implementation, input mctInput, decrypt bool) ([]byte, mctInput, error) {
+	b, err := aes.NewCipherWithImplementation(input.key, impl)
+	if err != nil {
+		return nil, mctInput{}, err
+	}
+
+	// segments is the IV split into segments which are fed as input of the first inner iterations
+	var segments [][]byte
+	switch mode {
+	case ModeECB:
+	case ModeCBC, ModeCFB128, ModeOFB:
+		segments = [][]byte{input.iv}
+	case ModeCFB8:
+		for _, c := range input.iv {
+			segments = append(segments, []byte{c})
+		}
+	case ModeCFB1:
+		for _, bit := range unpackBits(input.iv) {
+			segments = append(segments, []byte{bit})
+		}
+	default:
+		return nil, mctInput{}, ErrMode
+	}
+	n := len(segments)
+
+	outs := make([][]byte, mctInnerLoops)
+	in := input.text
+	chain := input.iv
+	for j := 0; j < mctInnerLoops; j++ {
+		out, err := crypt(b, mode, chain, in, decrypt)
+		if err != nil {
+			return nil, mctInput{}, err
+		}
+		outs[j] = out
+		chain = feedback(mode, chain, in, out, decrypt)
+
+		switch {
+		case mode == ModeECB:
+			in = out
+		case j < n:
+			in = segments[j]
+		default:
+			in = outs[j-n]
+		}
+	}
+
+	var stream []byte
+	if mode == ModeCFB1 {
+		bits := []byte{}
+		for _, out := range outs[mctInnerLoops-len(input.key)*8:] {
+			bits = append(bits, out...)
+		}
+		stream = packBits(bits)
+	} else {
+		for _, out := range outs {
+			stream = append(stream, out...)
+		}
+	}
+
+	next := mctInput{
+		key:  make([]byte, len(input.key)),
+		text: outs[mctInnerLoops-1-n],
+	}
+	tail := stream[len(stream)-len(input.key):]
+	for i := range next.key {
+		next.key[i] = input.key[i] ^ tail[i]
+	}
+	if mode != ModeECB {
+		next.iv = stream[len(stream)-blockSize:]
+	}
+	return outs[mctInnerLoops-1], next, nil
+}
+
+// feedback returns the chaining value of the next segment
+func feedback(mode Mode, chain, in, out []byte, decrypt bool) []byte {
+	cipherText := out
+	if decrypt {
+		cipherText = in
+	}
+
+	next := make([]byte, len(chain))
+	switch mode {
+	case ModeOFB:
+		// output of the block cipher
+		for i := range next {
+			next[i] = in[i] ^ out[i]
+		}
+	case ModeCFB8:
+		copy(next, chain[1:])
+		next[len(next)-1] = cipherText[0]
+	case ModeCFB1:
+		for i := 0; i < len(next)-1; i++ {
+			next[i] = chain[i]<<1 | chain[i+1]>>7
+		}
+		next[len(next)-1] = chain[len(next)-1]<<1 | cipherText[0]
+	default:
+		copy(next, cipherText)
+	}
+	return next
+}
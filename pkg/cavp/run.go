@@ -0,0 +1,189 @@
+package cavp
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+const (
+	// blockSize is the block size (byte) of AES
+	blockSize = 16
+	// mctInnerLoops is the number of iterations of the inner loop of Monte Carlo test
+	mctInnerLoops = 1000
+)
+
+// ErrMode is returned when the mode doesn't support the test
+var ErrMode = errors.New("unsupported mode for the test")
+
+// Result is the result of running a file
+type Result struct {
+	Name     string
+	Passed   int
+	Failed   int
+	Failures []Failure
+}
+
+// Failure is a vector whose output differs from the file.
+// In Monte Carlo test, a vector also fails when its inputs derived by the previous iteration differ from the file.
+type Failure struct {
+	Count   int
+	Decrypt bool
+	// Field is the name of the mismatched value, e.g. CIPHERTEXT or KEY.
+	// RunReseeded prefixes it with "next " for the inputs derived for the next vector.
+	Field    string
+	Output   []byte
+	Expected []byte
+}
+
+// OK returns true if every vector passed
+func (r *Result) OK() bool {
+	return r.Failed == 0
+}
+
+// Run runs every vector of the file with given implementation of pkg/aes.
+// Monte Carlo test runs a chain of outer iterations from COUNT = 0 as AESAVS Section 6.4.
+func Run(f *File, impl aes.Implementation) (*Result, error) {
+	if f.Test == TestMCT {
+		result := &Result{Name: f.Name}
+		if err := runMonteCarlo(f, impl, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return runVectors(f, impl)
+}
+
+// RunReseeded is a diagnostic to locate the broken outer iteration when Run fails a Monte Carlo test.
+// Each outer iteration starts from the inputs recorded in the file instead of the previous iteration,
+// so a failure doesn't spread to the following vectors. Passing it doesn't mean that the chain follows AESAVS.
+// The other tests run as Run.
+func RunReseeded(f *File, impl aes.Implementation) (*Result, error) {
+	if f.Test == TestMCT {
+		result := &Result{Name: f.Name}
+		if err := runMonteCarloReseeded(f, impl, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return runVectors(f, impl)
+}
+
+// runVectors runs each vector independently
+func runVectors(f *File, impl aes.Implementation) (*Result, error) {
+	result := &Result{Name: f.Name}
+	for _, v := range f.Vectors {
+		b, err := aes.NewCipherWithImplementation(v.Key, impl)
+		if err != nil {
+			return nil, err
+		}
+		in, expected := v.PlainText, v.CipherText
+		if v.Decrypt {
+			in, expected = v.CipherText, v.PlainText
+		}
+		out, err := crypt(b, f.Mode, v.IV, in, v.Decrypt)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(out, expected) {
+			result.Passed++
+		} else {
+			result.fail(v, outputField(v), out, expected)
+		}
+	}
+	return result, nil
+}
+
+// fail records the failure of the vector
+func (r *Result) fail(v Vector, field string, out, expected []byte) {
+	r.Failed++
+	r.Failures = append(r.Failures, Failure{
+		Count:    v.Count,
+		Decrypt:  v.Decrypt,
+		Field:    field,
+		Output:   out,
+		Expected: expected,
+	})
+}
+
+// outputField returns the name of the output of the vector
+func outputField(v Vector) string {
+	if v.Decrypt {
+		return "PLAINTEXT"
+	}
+	return "CIPHERTEXT"
+}
+
+// crypt encrypts or decrypts input with the mode of pkg/aes
+func crypt(b *aes.Block, mode Mode, iv, in []byte, decrypt bool) ([]byte, error) {
+	switch mode {
+	case ModeECB:
+		if decrypt {
+			return aes.ECBInvCipher(b, in, aes.NoPadding{})
+		}
+		return aes.ECBCipher(b, in, aes.NoPadding{})
+	case ModeCBC:
+		if decrypt {
+			return aes.CBCInvCipher(b, in, iv, aes.NoPadding{})
+		}
+		return aes.CBCCipher(b, in, iv, aes.NoPadding{})
+	case ModeCFB1:
+		return cryptBits(b, iv, in, decrypt)
+	case ModeCFB8:
+		if decrypt {
+			return aes.CFBSegmentInvCipher(b, in, iv, 8)
+		}
+		return aes.CFBSegmentCipher(b, in, iv, 8)
+	case ModeCFB128:
+		if decrypt {
+			return aes.CFBInvCipher(b, in, iv)
+		}
+		return aes.CFBCipher(b, in, iv)
+	case ModeOFB:
+		if decrypt {
+			return aes.OFBInvCipher(b, in, iv)
+		}
+		return aes.OFBCipher(b, in, iv)
+	case ModeCTR:
+		if decrypt {
+			return aes.CTRInvCipher(b, in, iv)
+		}
+		return aes.CTRCipher(b, in, iv)
+	}
+	return nil, ErrMode
+}
+
+// cryptBits encrypts or decrypts bits with CFB1 mode.
+// Bits are packed from the most significant bit and the output of padding bits is dropped.
+func cryptBits(b *aes.Block, iv, bits []byte, decrypt bool) ([]byte, error) {
+	var out []byte
+	var err error
+	if decrypt {
+		out, err = aes.CFBSegmentInvCipher(b, packBits(bits), iv, 1)
+	} else {
+		out, err = aes.CFBSegmentCipher(b, packBits(bits), iv, 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unpackBits(out)[:len(bits)], nil
+}
+
+// packBits packs bits into bytes from the most significant bit
+func packBits(bits []byte) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		packed[i/8] |= bit << uint(7-i%8)
+	}
+	return packed
+}
+
+// unpackBits unpacks bytes into bits from the most significant bit
+func unpackBits(packed []byte) []byte {
+	bits := make([]byte, len(packed)*8)
+	for i := range bits {
+		bits[i] = packed[i/8] >> uint(7-i%8) & 1
+	}
+	return bits
+}
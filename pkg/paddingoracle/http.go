@@ -0,0 +1,70 @@
+package paddingoracle
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// ErrOracleResponse is returned when the HTTP oracle responds with unexpected status
+var ErrOracleResponse = errors.New("unexpected response from oracle server")
+
+// NewHandler returns HTTP handler which answers the oracle.
+// It takes hexadecimal IV and cipher text as query parameters "iv" and "ciphertext" and responds
+// 200 OK if the padding is valid, 403 Forbidden if invalid and 400 Bad Request for malformed requests,
+// like a web application which leaks padding errors through its status code.
+func NewHandler(oracle Oracle) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		iv, err := hex.DecodeString(r.FormValue("iv"))
+		if err != nil {
+			http.Error(w, "invalid iv", http.StatusBadRequest)
+			return
+		}
+		cipherText, err := hex.DecodeString(r.FormValue("ciphertext"))
+		if err != nil {
+			http.Error(w, "invalid ciphertext", http.StatusBadRequest)
+			return
+		}
+
+		valid, err := oracle(iv, cipherText)
+		switch {
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case !valid:
+			http.Error(w, "invalid padding", http.StatusForbidden)
+		default:
+			w.Write([]byte("ok\n"))
+		}
+	})
+}
+
+// NewHTTPOracle returns Oracle which queries the handler of NewHandler served at given URL.
+// http.DefaultClient is used if client is nil.
+func NewHTTPOracle(serverURL string, client *http.Client) Oracle {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(iv, cipherText []byte) (bool, error) {
+		query := url.Values{}
+		query.Set("iv", hex.EncodeToString(iv))
+		query.Set("ciphertext", hex.EncodeToString(cipherText))
+		resp, err := client.Get(serverURL + "?" + query.Encode())
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+		// read the body to reuse the connection
+		io.Copy(ioutil.Discard, resp.Body)
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return true, nil
+		case http.StatusForbidden:
+			return false, nil
+		}
+		return false, ErrOracleResponse
+	}
+}
@@ -0,0 +1,54 @@
+package paddingoracle
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync/atomic"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+// LocalOracle is the padding oracle which decrypts with pkg/aes CBC mode under its secret key
+// and validates PKCS #7 padding strictly
+type LocalOracle struct {
+	block   *aes.Block
+	queries int64
+}
+
+// NewLocalOracle creates a new LocalOracle with given key
+func NewLocalOracle(key []byte) (*LocalOracle, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalOracle{block: b}, nil
+}
+
+// Encrypt encrypts given plain text with random IV. It's the victim's message to be attacked.
+func (o *LocalOracle) Encrypt(plainText []byte) ([]byte, []byte, error) {
+	iv := make([]byte, blockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+	cipherText, err := aes.CBCCipher(o.block, plainText, iv, aes.PKCS7Padding{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return iv, cipherText, nil
+}
+
+// Valid decrypts given cipher text and returns whether its padding is valid.
+// It's the Oracle of the local oracle and safe for concurrent use.
+func (o *LocalOracle) Valid(iv, cipherText []byte) (bool, error) {
+	atomic.AddInt64(&o.queries, 1)
+	_, err := aes.CBCInvCipher(o.block, cipherText, iv, aes.PKCS7Padding{})
+	if errors.Is(err, aes.ErrInvalidPadding) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Queries returns the number of queries answered so far
+func (o *LocalOracle) Queries() int {
+	return int(atomic.LoadInt64(&o.queries))
+}
@@ -0,0 +1,164 @@
+// Package paddingoracle implements Vaudenay's padding oracle attack on CBC mode with PKCS #7 padding.
+//
+// CBC decryption computes P[i] = D(C[i]) XOR C[i-1], so the attacker who controls C[i-1] controls P[i]
+// without knowing the key. An oracle which only tells whether the padding of a decrypted message is valid
+// reveals D(C[i]) byte by byte: the attacker changes the last byte of C[i-1] until the padding becomes 0x01,
+// then fixes it to 0x02 and searches the second to last byte, and so on.
+// Knowing D(C[i]) also lets the attacker choose C[i-1] so that C[i] decrypts to any plain text,
+// which forges the encryption of chosen messages from the last block backwards.
+package paddingoracle
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+// blockSize is the block size (byte) of AES
+const blockSize = 16
+
+// ErrNoValidPadding is returned when the oracle accepts none of the guesses for a byte
+var ErrNoValidPadding = errors.New("oracle accepted no guess")
+
+// Oracle reports whether the cipher text decrypts to a message with valid PKCS #7 padding
+type Oracle func(iv, cipherText []byte) (bool, error)
+
+// Stats is statistics of the oracle queries made by Attacker
+type Stats struct {
+	// Queries is the number of oracle queries
+	Queries int
+	// Blocks is the number of blocks whose decryption is recovered
+	Blocks int
+}
+
+// QueriesPerBlock returns the average number of queries to recover a block
+func (s Stats) QueriesPerBlock() float64 {
+	if s.Blocks == 0 {
+		return 0
+	}
+	return float64(s.Queries) / float64(s.Blocks)
+}
+
+// QueriesPerByte returns the average number of queries to recover a byte
+func (s Stats) QueriesPerByte() float64 {
+	return s.QueriesPerBlock() / blockSize
+}
+
+// Attacker decrypts and forges cipher texts using only the padding oracle
+type Attacker struct {
+	oracle Oracle
+	stats  Stats
+}
+
+// NewAttacker creates a new Attacker which queries given oracle
+func NewAttacker(oracle Oracle) *Attacker {
+	return &Attacker{oracle: oracle}
+}
+
+// Stats returns the statistics of queries made so far
+func (a *Attacker) Stats() Stats {
+	return a.stats
+}
+
+// Decrypt recovers the plain text of given cipher text and removes its padding
+func (a *Attacker) Decrypt(iv, cipherText []byte) ([]byte, error) {
+	if len(iv) != blockSize {
+		return nil, aes.ErrIVSize
+	}
+	if len(cipherText) == 0 || len(cipherText)%blockSize != 0 {
+		return nil, aes.ErrCiphertextLength
+	}
+
+	plainText := make([]byte, len(cipherText))
+	previous := iv
+	for from := 0; from < len(cipherText); from += blockSize {
+		block := cipherText[from : from+blockSize]
+		intermediate, err := a.decryptBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < blockSize; j++ {
+			plainText[from+j] = intermediate[j] ^ previous[j]
+		}
+		previous = block
+	}
+	return aes.PKCS7Padding{}.Unpad(plainText, blockSize)
+}
+
+// Encrypt forges the cipher text of given plain text with PKCS #7 padding.
+// The last block is random and each previous block (and IV) is chosen so that the next block decrypts to the plain text.
+func (a *Attacker) Encrypt(plainText []byte) ([]byte, []byte, error) {
+	padded, err := aes.PKCS7Padding{}.Pad(plainText, blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cipherText := make([]byte, len(padded))
+	block := make([]byte, blockSize)
+	if _, err := rand.Read(block); err != nil {
+		return nil, nil, err
+	}
+	for to := len(padded); to > 0; to -= blockSize {
+		copy(cipherText[to-blockSize:to], block)
+		intermediate, err := a.decryptBlock(block)
+		if err != nil {
+			return nil, nil, err
+		}
+		// previous block which makes this block decrypt to the plain text
+		block = make([]byte, blockSize)
+		for j := 0; j < blockSize; j++ {
+			block[j] = intermediate[j] ^ padded[to-blockSize+j]
+		}
+	}
+	return block, cipherText, nil
+}
+
+// decryptBlock recovers D(block), the block cipher decryption of given block before XOR with the previous block.
+// The oracle is queried with a forged IV followed by the block.
+func (a *Attacker) decryptBlock(block []byte) ([]byte, error) {
+	intermediate := make([]byte, blockSize)
+	forged := make([]byte, blockSize)
+
+	for padding := 1; padding <= blockSize; padding++ {
+		i := blockSize - padding
+		// make the bytes after i decrypt to the padding value
+		for j := i + 1; j < blockSize; j++ {
+			forged[j] = intermediate[j] ^ byte(padding)
+		}
+
+		found := false
+		for guess := 0; guess < 256 && !found; guess++ {
+			forged[i] = byte(guess)
+			valid, err := a.query(forged, block)
+			if err != nil {
+				return nil, err
+			}
+			if valid && padding == 1 && i > 0 {
+				// the padding may be longer than 1, e.g. 0x02 0x02, by chance.
+				// It's 0x01 if changing the second to last byte keeps it valid.
+				forged[i-1] ^= 0xff
+				valid, err = a.query(forged, block)
+				forged[i-1] ^= 0xff
+				if err != nil {
+					return nil, err
+				}
+			}
+			if valid {
+				intermediate[i] = byte(guess) ^ byte(padding)
+				found = true
+			}
+		}
+		if !found {
+			return nil, ErrNoValidPadding
+		}
+	}
+	a.stats.Blocks++
+	return intermediate, nil
+}
+
+// query asks the oracle and counts the query
+func (a *Attacker) query(iv, cipherText []byte) (bool, error) {
+	a.stats.Queries++
+	return a.oracle(iv, cipherText)
+}
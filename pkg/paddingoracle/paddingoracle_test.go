@@ -0,0 +1,157 @@
+package paddingoracle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+// newTestOracle returns LocalOracle with random key and the key
+func newTestOracle(t *testing.T) (*LocalOracle, []byte) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	oracle, err := NewLocalOracle(key)
+	if err != nil {
+		t.Fatalf("failed to create oracle: %v", err)
+	}
+	return oracle, key
+}
+
+func TestDecrypt(t *testing.T) {
+	oracle, _ := newTestOracle(t)
+	messages := [][]byte{
+		[]byte(""),
+		[]byte("A"),
+		[]byte("YELLOW SUBMARINE"),
+		[]byte("attack at dawn, retreat at dusk!!"),
+		{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02},
+	}
+	for i, message := range messages {
+		iv, cipherText, err := oracle.Encrypt(message)
+		if err != nil {
+			t.Fatalf("[TestDecrypt] case %d failed: %v", i, err)
+		}
+		before := oracle.Queries()
+
+		attacker := NewAttacker(oracle.Valid)
+		plainText, err := attacker.Decrypt(iv, cipherText)
+		if err != nil {
+			t.Fatalf("[TestDecrypt] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(plainText, message) {
+			t.Errorf("[TestDecrypt] case %d failed: plainText != expected : '%x' != '%x'", i, plainText, message)
+		}
+
+		stats := attacker.Stats()
+		if stats.Blocks != len(cipherText)/16 {
+			t.Errorf("[TestDecrypt] case %d failed: blocks %d, but expected %d", i, stats.Blocks, len(cipherText)/16)
+		}
+		if stats.Queries != oracle.Queries()-before {
+			t.Errorf("[TestDecrypt] case %d failed: queries %d, but oracle answered %d", i, stats.Queries, oracle.Queries()-before)
+		}
+		// at most 256 guesses and a check of the last byte for each byte
+		if stats.QueriesPerBlock() > 256*16+1 {
+			t.Errorf("[TestDecrypt] case %d failed: %f queries per block", i, stats.QueriesPerBlock())
+		}
+		t.Logf("[TestDecrypt] case %d: %d queries (%.1f per byte)", i, stats.Queries, stats.QueriesPerByte())
+	}
+
+	attacker := NewAttacker(oracle.Valid)
+	if _, err := attacker.Decrypt(make([]byte, 16), make([]byte, 20)); !errors.Is(err, aes.ErrCiphertextLength) {
+		t.Errorf("[TestDecrypt] failed: err '%v', but expected '%v'", err, aes.ErrCiphertextLength)
+	}
+	if _, err := attacker.Decrypt(make([]byte, 8), make([]byte, 16)); !errors.Is(err, aes.ErrIVSize) {
+		t.Errorf("[TestDecrypt] failed: err '%v', but expected '%v'", err, aes.ErrIVSize)
+	}
+}
+
+func TestDecryptBlockFalsePositive(t *testing.T) {
+	// D(block) ends with 0x02 0x03, so the guess 0x01 for the last byte makes padding 0x02 0x02
+	// before the right guess 0x02 makes padding 0x01
+	intermediate := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0x02, 0x03}
+	oracle := func(iv, cipherText []byte) (bool, error) {
+		plainText := make([]byte, 16)
+		for j := range plainText {
+			plainText[j] = intermediate[j] ^ iv[j]
+		}
+		_, err := aes.PKCS7Padding{}.Unpad(plainText, 16)
+		return err == nil, nil
+	}
+
+	result, err := NewAttacker(oracle).decryptBlock(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("[TestDecryptBlockFalsePositive] failed: %v", err)
+	}
+	if !bytes.Equal(result, intermediate) {
+		t.Errorf("[TestDecryptBlockFalsePositive] failed: result != expected : '%x' != '%x'", result, intermediate)
+	}
+
+	never := func(iv, cipherText []byte) (bool, error) {
+		return false, nil
+	}
+	if _, err := NewAttacker(never).decryptBlock(make([]byte, 16)); !errors.Is(err, ErrNoValidPadding) {
+		t.Errorf("[TestDecryptBlockFalsePositive] failed: err '%v', but expected '%v'", err, ErrNoValidPadding)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	oracle, key := newTestOracle(t)
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("[TestEncrypt] failed: %v", err)
+	}
+
+	messages := [][]byte{
+		[]byte("admin=true"),
+		[]byte("{\"user\":\"mallory\",\"role\":\"admin\"}"),
+	}
+	for i, message := range messages {
+		attacker := NewAttacker(oracle.Valid)
+		iv, cipherText, err := attacker.Encrypt(message)
+		if err != nil {
+			t.Fatalf("[TestEncrypt] case %d failed: %v", i, err)
+		}
+		// the owner of the key decrypts the forged cipher text to the chosen message
+		plainText, err := aes.CBCInvCipher(b, cipherText, iv, aes.PKCS7Padding{})
+		if err != nil {
+			t.Fatalf("[TestEncrypt] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(plainText, message) {
+			t.Errorf("[TestEncrypt] case %d failed: plainText != expected : '%x' != '%x'", i, plainText, message)
+		}
+		if stats := attacker.Stats(); stats.Blocks != len(cipherText)/16 {
+			t.Errorf("[TestEncrypt] case %d failed: blocks %d, but expected %d", i, stats.Blocks, len(cipherText)/16)
+		}
+	}
+}
+
+func TestHTTPOracle(t *testing.T) {
+	oracle, _ := newTestOracle(t)
+	server := httptest.NewServer(NewHandler(oracle.Valid))
+	defer server.Close()
+
+	message := []byte("leaked over HTTP")
+	iv, cipherText, err := oracle.Encrypt(message)
+	if err != nil {
+		t.Fatalf("[TestHTTPOracle] failed: %v", err)
+	}
+	httpOracle := NewHTTPOracle(server.URL, server.Client())
+	plainText, err := NewAttacker(httpOracle).Decrypt(iv, cipherText)
+	if err != nil {
+		t.Fatalf("[TestHTTPOracle] failed: %v", err)
+	}
+	if !bytes.Equal(plainText, message) {
+		t.Errorf("[TestHTTPOracle] failed: plainText != expected : '%x' != '%x'", plainText, message)
+	}
+
+	// cipher text which isn't a multiple of block size is a bad request
+	if _, err := httpOracle(iv, cipherText[:20]); !errors.Is(err, ErrOracleResponse) {
+		t.Errorf("[TestHTTPOracle] failed: err '%v', but expected '%v'", err, ErrOracleResponse)
+	}
+}
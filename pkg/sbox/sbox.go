@@ -0,0 +1,339 @@
+// Package sbox analyzes cryptographic properties of n-bit to n-bit substitution boxes
+// such as the difference distribution table and the linear approximation table.
+package sbox
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+// maxBits is the maximum input size (bit) of S-box. DDT and LAT have 4^n entries,
+// so 12-bit S-box needs about 128 MiB for each table.
+const maxBits = 12
+
+var (
+	// ErrSize is returned when the number of entries isn't 2^n
+	ErrSize = errors.New("number of S-box entries must be 2^n")
+	// ErrValue is returned when an entry doesn't fit in n bit
+	ErrValue = errors.New("S-box entry is out of range")
+	// ErrNotPermutation is returned when the operation needs bijective S-box
+	ErrNotPermutation = errors.New("S-box is not a permutation")
+)
+
+// SBox is an n-bit to n-bit substitution box
+type SBox struct {
+	bits  int
+	table []int
+}
+
+// Summary is the result of Analyze
+type Summary struct {
+	Bits                   int
+	Permutation            bool
+	DifferentialUniformity int
+	Nonlinearity           int
+	MaxLinearBias          int // maximum absolute value in LAT except b = 0
+	AlgebraicDegree        int
+	FixedPoints            []int
+	CycleLengths           []int // lengths of cycles. It's nil if S-box is not a permutation.
+}
+
+// New returns SBox of given table. The length of table must be 2^n and every entry must be less than 2^n.
+func New(table []int) (*SBox, error) {
+	n := bits.Len(uint(len(table))) - 1
+	if n < 1 || n > maxBits || len(table) != 1<<uint(n) {
+		return nil, ErrSize
+	}
+	t := make([]int, len(table))
+	for i, v := range table {
+		if v < 0 || v >= len(table) {
+			return nil, ErrValue
+		}
+		t[i] = v
+	}
+	return &SBox{
+		bits:  n,
+		table: t,
+	}, nil
+}
+
+// AES returns the S-box of AES
+func AES() *SBox {
+	return fromByteTransform(aes.SubBytes)
+}
+
+// AESInverse returns the inverse S-box of AES
+func AESInverse() *SBox {
+	return fromByteTransform(aes.InvSubBytes)
+}
+
+// fromByteTransform returns 8-bit SBox which is the result of given transformation of every byte
+func fromByteTransform(transform func([]byte)) *SBox {
+	in := make([]byte, 256)
+	for x := range in {
+		in[x] = byte(x)
+	}
+	transform(in)
+
+	table := make([]int, 256)
+	for x, v := range in {
+		table[x] = int(v)
+	}
+	return &SBox{
+		bits:  8,
+		table: table,
+	}
+}
+
+// Parse reads S-box entries in hexadecimal separated by white spaces or commas
+func Parse(r io.Reader) (*SBox, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.FieldsFunc(string(data), func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r'
+	})
+
+	table := make([]int, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(f), "0x"), 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		table[i] = int(v)
+	}
+	return New(table)
+}
+
+// Bits returns the input and output size (bit)
+func (s *SBox) Bits() int {
+	return s.bits
+}
+
+// Lookup returns S(x)
+func (s *SBox) Lookup(x int) int {
+	return s.table[x]
+}
+
+// size returns the number of entries
+func (s *SBox) size() int {
+	return len(s.table)
+}
+
+// DDT returns the difference distribution table.
+// DDT[a][b] is the number of x which satisfy S(x) XOR S(x XOR a) = b.
+func (s *SBox) DDT() [][]int {
+	n := s.size()
+	ddt := newTable(n)
+	for a := 0; a < n; a++ {
+		for x := 0; x < n; x++ {
+			ddt[a][s.table[x]^s.table[x^a]]++
+		}
+	}
+	return ddt
+}
+
+// LAT returns the linear approximation table.
+// LAT[a][b] is the number of x which satisfy a·x = b·S(x) minus 2^(n-1).
+// Each column is calculated by fast Walsh-Hadamard transform of (-1)^(b·S(x)) in O(n 2^n),
+// which gives W[a] = Σ (-1)^(a·x XOR b·S(x)) = 2 LAT[a][b].
+func (s *SBox) LAT() [][]int {
+	n := s.size()
+	lat := newTable(n)
+	w := make([]int, n)
+	for b := 0; b < n; b++ {
+		for x := 0; x < n; x++ {
+			w[x] = 1 - 2*(bits.OnesCount(uint(b&s.table[x]))&1)
+		}
+		// fast Walsh-Hadamard transform
+		for step := 1; step < n; step <<= 1 {
+			for x := 0; x < n; x++ {
+				if x&step == 0 {
+					w[x], w[x|step] = w[x]+w[x|step], w[x]-w[x|step]
+				}
+			}
+		}
+		for a := 0; a < n; a++ {
+			lat[a][b] = w[a] / 2
+		}
+	}
+	return lat
+}
+
+// DifferentialUniformity returns the maximum entry of DDT except a = 0
+func (s *SBox) DifferentialUniformity() int {
+	return maxDifferential(s.DDT())
+}
+
+// Nonlinearity returns the minimum Hamming distance between component functions and affine functions.
+// It's 2^(n-1) minus the maximum absolute value of LAT except b = 0.
+func (s *SBox) Nonlinearity() int {
+	return s.size()/2 - maxLinearBias(s.LAT())
+}
+
+// AlgebraicDegree returns the maximum algebraic degree of coordinate functions.
+// The degree is calculated from the algebraic normal form obtained by Möbius transform.
+func (s *SBox) AlgebraicDegree() int {
+	n := s.size()
+	degree := 0
+	for bit := 0; bit < s.bits; bit++ {
+		anf := make([]int, n)
+		for x := 0; x < n; x++ {
+			anf[x] = s.table[x] >> uint(bit) & 1
+		}
+		// Möbius transform
+		for step := 1; step < n; step <<= 1 {
+			for x := 0; x < n; x++ {
+				if x&step != 0 {
+					anf[x] ^= anf[x^step]
+				}
+			}
+		}
+		for monomial, c := range anf {
+			if c == 1 && bits.OnesCount(uint(monomial)) > degree {
+				degree = bits.OnesCount(uint(monomial))
+			}
+		}
+	}
+	return degree
+}
+
+// FixedPoints returns x which satisfy S(x) = x
+func (s *SBox) FixedPoints() []int {
+	points := []int{}
+	for x, v := range s.table {
+		if x == v {
+			points = append(points, x)
+		}
+	}
+	return points
+}
+
+// IsPermutation returns true if S-box is bijective
+func (s *SBox) IsPermutation() bool {
+	seen := make([]bool, s.size())
+	for _, v := range s.table {
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+// Cycles returns the cycle decomposition of S-box.
+// Each cycle starts from its smallest element and cycles are ordered by the first element.
+func (s *SBox) Cycles() ([][]int, error) {
+	if !s.IsPermutation() {
+		return nil, ErrNotPermutation
+	}
+
+	visited := make([]bool, s.size())
+	cycles := [][]int{}
+	for start := range s.table {
+		if visited[start] {
+			continue
+		}
+		cycle := []int{}
+		for x := start; !visited[x]; x = s.table[x] {
+			visited[x] = true
+			cycle = append(cycle, x)
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles, nil
+}
+
+// Analyze calculates every property of S-box
+func (s *SBox) Analyze() Summary {
+	summary := Summary{
+		Bits:                   s.bits,
+		Permutation:            s.IsPermutation(),
+		DifferentialUniformity: s.DifferentialUniformity(),
+		AlgebraicDegree:        s.AlgebraicDegree(),
+		FixedPoints:            s.FixedPoints(),
+	}
+	summary.MaxLinearBias = maxLinearBias(s.LAT())
+	summary.Nonlinearity = s.size()/2 - summary.MaxLinearBias
+
+	if cycles, err := s.Cycles(); err == nil {
+		for _, c := range cycles {
+			summary.CycleLengths = append(summary.CycleLengths, len(c))
+		}
+	}
+	return summary
+}
+
+// WriteCSV writes given table (DDT or LAT) as CSV.
+// The first row and column are the output and input masks (differences).
+func WriteCSV(w io.Writer, table [][]int) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(table)+1)
+	header[0] = "in\\out"
+	for b := range table {
+		header[b+1] = strconv.Itoa(b)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for a, row := range table {
+		record := make([]string, len(row)+1)
+		record[0] = strconv.Itoa(a)
+		for b, v := range row {
+			record[b+1] = strconv.Itoa(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// newTable returns n x n table filled with zero
+func newTable(n int) [][]int {
+	table := make([][]int, n)
+	for i := range table {
+		table[i] = make([]int, n)
+	}
+	return table
+}
+
+// maxDifferential returns the maximum entry of DDT except a = 0
+func maxDifferential(ddt [][]int) int {
+	max := 0
+	for a := 1; a < len(ddt); a++ {
+		for _, v := range ddt[a] {
+			if v > max {
+				max = v
+			}
+		}
+	}
+	return max
+}
+
+// maxLinearBias returns the maximum absolute entry of LAT except b = 0
+func maxLinearBias(lat [][]int) int {
+	max := 0
+	for a := range lat {
+		for b := 1; b < len(lat[a]); b++ {
+			v := lat[a][b]
+			if v < 0 {
+				v = -v
+			}
+			if v > max {
+				max = v
+			}
+		}
+	}
+	return max
+}
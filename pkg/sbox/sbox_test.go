@@ -0,0 +1,159 @@
+package sbox
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// present is the 4-bit S-box of PRESENT block cipher
+var present = []int{0xc, 0x5, 0x6, 0xb, 0x9, 0x0, 0xa, 0xd, 0x3, 0xe, 0xf, 0x8, 0x4, 0x7, 0x1, 0x2}
+
+func TestAnalyzeAES(t *testing.T) {
+	for _, s := range []*SBox{AES(), AESInverse()} {
+		summary := s.Analyze()
+		if summary.Bits != 8 || !summary.Permutation {
+			t.Errorf("[TestAnalyzeAES] failed: bits %d, permutation %v", summary.Bits, summary.Permutation)
+		}
+		if summary.DifferentialUniformity != 4 {
+			t.Errorf("[TestAnalyzeAES] failed: differential uniformity %d, but expected 4", summary.DifferentialUniformity)
+		}
+		if summary.Nonlinearity != 112 || summary.MaxLinearBias != 16 {
+			t.Errorf("[TestAnalyzeAES] failed: nonlinearity %d and max bias %d, but expected 112 and 16", summary.Nonlinearity, summary.MaxLinearBias)
+		}
+		if summary.AlgebraicDegree != 7 {
+			t.Errorf("[TestAnalyzeAES] failed: algebraic degree %d, but expected 7", summary.AlgebraicDegree)
+		}
+		if len(summary.FixedPoints) != 0 {
+			t.Errorf("[TestAnalyzeAES] failed: fixed points %v, but expected none", summary.FixedPoints)
+		}
+	}
+
+	// cycle structure of the AES S-box
+	cycles := AES().Analyze().CycleLengths
+	sort.Ints(cycles)
+	if expected := []int{2, 27, 59, 81, 87}; !reflect.DeepEqual(cycles, expected) {
+		t.Errorf("[TestAnalyzeAES] failed: cycle lengths %v, but expected %v", cycles, expected)
+	}
+}
+
+func TestAnalyzePresent(t *testing.T) {
+	s, err := New(present)
+	if err != nil {
+		t.Fatalf("[TestAnalyzePresent] failed: %v", err)
+	}
+	summary := s.Analyze()
+	if summary.Bits != 4 || summary.DifferentialUniformity != 4 || summary.Nonlinearity != 4 || summary.AlgebraicDegree != 3 {
+		t.Errorf("[TestAnalyzePresent] failed: unexpected summary %+v", summary)
+	}
+
+	ddt := s.DDT()
+	if ddt[0][0] != 16 {
+		t.Errorf("[TestAnalyzePresent] failed: DDT[0][0] is %d, but expected 16", ddt[0][0])
+	}
+	for a, row := range ddt {
+		sum := 0
+		for _, v := range row {
+			sum += v
+			if v%2 != 0 {
+				t.Errorf("[TestAnalyzePresent] failed: DDT row %d has odd entry %d", a, v)
+			}
+		}
+		if sum != 16 {
+			t.Errorf("[TestAnalyzePresent] failed: sum of DDT row %d is %d, but expected 16", a, sum)
+		}
+	}
+	if lat := s.LAT(); lat[0][0] != 8 {
+		t.Errorf("[TestAnalyzePresent] failed: LAT[0][0] is %d, but expected 8", lat[0][0])
+	}
+}
+
+func TestLAT(t *testing.T) {
+	// compare with the definition of LAT
+	sboxes := []*SBox{AES()}
+	s, err := New(present)
+	if err != nil {
+		t.Fatalf("[TestLAT] failed: %v", err)
+	}
+	sboxes = append(sboxes, s)
+
+	for _, s := range sboxes {
+		n := s.size()
+		lat := s.LAT()
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				count := 0
+				for x := 0; x < n; x++ {
+					if bits.OnesCount(uint(a&x))&1 == bits.OnesCount(uint(b&s.Lookup(x)))&1 {
+						count++
+					}
+				}
+				if lat[a][b] != count-n/2 {
+					t.Fatalf("[TestLAT] %d-bit failed: LAT[%d][%d] is %d, but expected %d", s.Bits(), a, b, lat[a][b], count-n/2)
+				}
+			}
+		}
+	}
+}
+
+func TestFixedPointsAndCycles(t *testing.T) {
+	// identity on 0, 1 and a cycle of 2, 3
+	s, err := New([]int{0, 1, 3, 2})
+	if err != nil {
+		t.Fatalf("[TestFixedPointsAndCycles] failed: %v", err)
+	}
+	if points := s.FixedPoints(); !reflect.DeepEqual(points, []int{0, 1}) {
+		t.Errorf("[TestFixedPointsAndCycles] failed: fixed points %v, but expected [0 1]", points)
+	}
+	cycles, err := s.Cycles()
+	if err != nil {
+		t.Fatalf("[TestFixedPointsAndCycles] failed: %v", err)
+	}
+	if expected := [][]int{{0}, {1}, {2, 3}}; !reflect.DeepEqual(cycles, expected) {
+		t.Errorf("[TestFixedPointsAndCycles] failed: cycles %v, but expected %v", cycles, expected)
+	}
+
+	s, err = New([]int{0, 0, 1, 1})
+	if err != nil {
+		t.Fatalf("[TestFixedPointsAndCycles] failed: %v", err)
+	}
+	if _, err := s.Cycles(); !errors.Is(err, ErrNotPermutation) {
+		t.Errorf("[TestFixedPointsAndCycles] failed: err '%v', but expected '%v'", err, ErrNotPermutation)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New([]int{0, 1, 2}); !errors.Is(err, ErrSize) {
+		t.Errorf("[TestNewErrors] failed: err '%v', but expected '%v'", err, ErrSize)
+	}
+	if _, err := New([]int{0, 4, 2, 1}); !errors.Is(err, ErrValue) {
+		t.Errorf("[TestNewErrors] failed: err '%v', but expected '%v'", err, ErrValue)
+	}
+}
+
+func TestParse(t *testing.T) {
+	s, err := Parse(strings.NewReader("0xc, 5, 6, b\n9 0 a d\n3,e,f,8,4,7,1,2\n"))
+	if err != nil {
+		t.Fatalf("[TestParse] failed: %v", err)
+	}
+	for x, v := range present {
+		if s.Lookup(x) != v {
+			t.Errorf("[TestParse] case %d failed: result '%x', but expected '%x'", x, s.Lookup(x), v)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, [][]int{{2, 0}, {0, -2}}); err != nil {
+		t.Fatalf("[TestWriteCSV] failed: %v", err)
+	}
+	expected := "in\\out,0,1\n0,2,0\n1,0,-2\n"
+	if buf.String() != expected {
+		t.Errorf("[TestWriteCSV] failed: result %q, but expected %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,146 @@
+// Package square implements the Square (integral) attack on 4-round AES-128.
+//
+// A delta-set is 256 plain texts which take every value in one byte and are constant in the others.
+// After 3 rounds of AES, every byte of the state XORed over a delta-set is 0 (balanced).
+// The attacker guesses each byte of the last round key, partially decrypts the last round
+// and keeps only guesses which make the state before the last round balanced.
+package square
+
+import (
+	"errors"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+const (
+	// Rounds is the number of rounds of AES which the attack targets
+	Rounds = 4
+	// blockSize is the block size (byte) of AES
+	blockSize = 16
+	// maxDeltaSets is the maximum number of delta-sets used to filter wrong key guesses.
+	// A wrong guess survives a delta-set with probability 1/256.
+	maxDeltaSets = 8
+)
+
+// ErrKeyNotFound is returned when the attack can't determine the key
+var ErrKeyNotFound = errors.New("key not found")
+
+// Oracle encrypts given plain text with 4-round AES-128 under the unknown key
+type Oracle func(plainText []byte) []byte
+
+// invSbox is the AES inverse S-box as lookup table
+var invSbox [256]byte
+
+func init() {
+	for x := 0; x < 256; x++ {
+		invSbox[x] = byte(x)
+	}
+	aes.InvSubBytes(invSbox[:])
+}
+
+// DeltaSet returns 256 plain texts where byte active takes every value and the others are given constant
+func DeltaSet(constant []byte, active int) [][]byte {
+	set := make([][]byte, 256)
+	for i := range set {
+		p := make([]byte, blockSize)
+		copy(p, constant)
+		p[active] = byte(i)
+		set[i] = p
+	}
+	return set
+}
+
+// Attack recovers the cipher key of 4-round AES-128 by querying oracle with delta-sets.
+// It returns ErrKeyNotFound if the recovered key doesn't reproduce the oracle.
+func Attack(oracle Oracle) ([]byte, error) {
+	roundKey, err := RecoverLastRoundKey(oracle)
+	if err != nil {
+		return nil, err
+	}
+	key, err := aes.InvertKeySchedule(roundKey, aes.BlockSize128*Rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	// confirm the key with a fresh plain text
+	b, err := aes.NewCipherWithRounds(key, Rounds)
+	if err != nil {
+		return nil, err
+	}
+	plainText := make([]byte, blockSize)
+	for i := range plainText {
+		plainText[i] = byte(0xa5 ^ i)
+	}
+	cipherText := make([]byte, blockSize)
+	b.Encrypt(cipherText, plainText)
+	expected := oracle(plainText)
+	for i := range cipherText {
+		if cipherText[i] != expected[i] {
+			return nil, ErrKeyNotFound
+		}
+	}
+	return key, nil
+}
+
+// RecoverLastRoundKey recovers the round key of the last (4th) round.
+// Each key byte is guessed independently and guesses which don't balance the state
+// before the last round are discarded until only one remains.
+func RecoverLastRoundKey(oracle Oracle) ([]byte, error) {
+	// candidates[j][k] is true while k is still a candidate of key byte j
+	var candidates [blockSize][256]bool
+	for j := range candidates {
+		for k := range candidates[j] {
+			candidates[j][k] = true
+		}
+	}
+
+	for d := 0; d < maxDeltaSets; d++ {
+		constant := make([]byte, blockSize)
+		for i := range constant {
+			constant[i] = byte(d)
+		}
+		cipherTexts := make([][]byte, 256)
+		for i, p := range DeltaSet(constant, 0) {
+			cipherTexts[i] = oracle(p)
+		}
+
+		for j := 0; j < blockSize; j++ {
+			for k := 0; k < 256; k++ {
+				if !candidates[j][k] {
+					continue
+				}
+				// the last round has no MixColumns, so a byte is decrypted with a key byte only
+				var sum byte
+				for _, c := range cipherTexts {
+					sum ^= invSbox[c[j]^byte(k)]
+				}
+				if sum != 0 {
+					candidates[j][k] = false
+				}
+			}
+		}
+
+		if key, ok := uniqueKey(&candidates); ok {
+			return key, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// uniqueKey returns the key if every key byte has exactly one candidate
+func uniqueKey(candidates *[blockSize][256]bool) ([]byte, bool) {
+	key := make([]byte, blockSize)
+	for j := range candidates {
+		n := 0
+		for k, ok := range candidates[j] {
+			if ok {
+				key[j] = byte(k)
+				n++
+			}
+		}
+		if n != 1 {
+			return nil, false
+		}
+	}
+	return key, true
+}
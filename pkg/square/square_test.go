@@ -0,0 +1,76 @@
+package square
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/mas9612/cryptostudy/pkg/aes"
+)
+
+func TestAttack(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		key := make([]byte, 16)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatalf("[TestAttack] case %d failed: %v", i, err)
+		}
+		b, err := aes.NewCipherWithRounds(key, Rounds)
+		if err != nil {
+			t.Fatalf("[TestAttack] case %d failed: %v", i, err)
+		}
+
+		queries := 0
+		oracle := func(plainText []byte) []byte {
+			queries++
+			cipherText := make([]byte, 16)
+			b.Encrypt(cipherText, plainText)
+			return cipherText
+		}
+		recovered, err := Attack(oracle)
+		if err != nil {
+			t.Fatalf("[TestAttack] case %d failed: %v", i, err)
+		}
+		if !bytes.Equal(recovered, key) {
+			t.Errorf("[TestAttack] case %d failed: recovered key '%x', but expected '%x'", i, recovered, key)
+		}
+		t.Logf("[TestAttack] case %d: key recovered with %d queries", i, queries)
+	}
+}
+
+func TestAttackMoreRounds(t *testing.T) {
+	// the integral property doesn't hold for 5 rounds
+	key := []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	b, err := aes.NewCipherWithRounds(key, Rounds+1)
+	if err != nil {
+		t.Fatalf("[TestAttackMoreRounds] failed: %v", err)
+	}
+	oracle := func(plainText []byte) []byte {
+		cipherText := make([]byte, 16)
+		b.Encrypt(cipherText, plainText)
+		return cipherText
+	}
+	if _, err := Attack(oracle); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("[TestAttackMoreRounds] failed: err '%v', but expected '%v'", err, ErrKeyNotFound)
+	}
+}
+
+func TestDeltaSet(t *testing.T) {
+	constant := make([]byte, 16)
+	set := DeltaSet(constant, 5)
+	if len(set) != 256 {
+		t.Fatalf("[TestDeltaSet] failed: length %d, but expected 256", len(set))
+	}
+	seen := make(map[byte]bool)
+	for i, p := range set {
+		for j := range p {
+			if j != 5 && p[j] != 0 {
+				t.Errorf("[TestDeltaSet] case %d failed: passive byte %d is '%x'", i, j, p[j])
+			}
+		}
+		seen[p[5]] = true
+	}
+	if len(seen) != 256 {
+		t.Errorf("[TestDeltaSet] failed: active byte takes %d values, but expected 256", len(seen))
+	}
+}